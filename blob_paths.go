@@ -0,0 +1,102 @@
+package srcfingerprint
+
+import (
+	"context"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// treeMode is the mode of the tree entries which are trees.
+const treeMode = "40000"
+
+// blobPaths returns all the paths of each blob in the trees of the commits selected by revListArgs,
+// as git rev-list --objects names each blob with one of its paths only.
+// Each tree is walked once for each of its paths, so the trees shared by commits are read once.
+func blobPaths(ctx context.Context, repositoryPath string, revListArgs []string) (map[string][]string, error) {
+	roots, err := rootTrees(ctx, repositoryPath, revListArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := newCatFileBatch(ctx, repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = objects.Close() }()
+
+	type pendingTree struct {
+		sha  string
+		path string
+	}
+
+	pending := make([]pendingTree, 0, len(roots))
+	for _, root := range roots {
+		pending = append(pending, pendingTree{sha: root})
+	}
+
+	walked := make(map[pendingTree]bool)
+	found := make(map[string]bool)
+	paths := make(map[string][]string)
+
+	for len(pending) > 0 {
+		tree := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if walked[tree] {
+			continue
+		}
+
+		walked[tree] = true
+
+		entries, err := readTree(objects, tree.sha)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			entryPath := path.Join(tree.path, entry.name)
+
+			switch entry.mode {
+			case treeMode:
+				pending = append(pending, pendingTree{sha: entry.sha, path: entryPath})
+			case gitlinkMode:
+				// Submodules are not blobs
+			default:
+				if !found[entry.sha+"\x00"+entryPath] {
+					found[entry.sha+"\x00"+entryPath] = true
+					paths[entry.sha] = append(paths[entry.sha], entryPath)
+				}
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// rootTrees returns the distinct root trees of the commits selected by revListArgs.
+func rootTrees(ctx context.Context, repositoryPath string, revListArgs []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"rev-list", "--format=%T"}, revListArgs...)...)
+	cmd.Dir = repositoryPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	trees := make([]string, 0)
+
+	// Each commit is a "commit <sha>" line followed by the line of its tree
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" || strings.HasPrefix(line, "commit ") || seen[line] {
+			continue
+		}
+
+		seen[line] = true
+		trees = append(trees, line)
+	}
+
+	return trees, nil
+}
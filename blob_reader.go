@@ -0,0 +1,120 @@
+package srcfingerprint
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// blobReadFunc reads the content of gitFile, it can update gitFile and returns the records derived from the content.
+type blobReadFunc func(gitFile *GitFile, content io.Reader) ([]*GitFile, error)
+
+// blobReader reads the content of blobs with a pool of `git cat-file --batch` processes,
+// and publishes each blob followed by the records derived from its content.
+// When ctx is done, the blobs are dropped and the processes are stopped.
+type blobReader struct {
+	ctx   context.Context
+	blobs chan *GitFile
+	read  blobReadFunc
+	// publish returns false if the record could not be published because ctx is done
+	publish func(*GitFile) bool
+	wg      sync.WaitGroup
+}
+
+func newBlobReader(
+	ctx context.Context,
+	path string,
+	read blobReadFunc,
+	publish func(*GitFile) bool) (*blobReader, error) {
+	reader := &blobReader{ctx: ctx, blobs: make(chan *GitFile), read: read, publish: publish}
+
+	workers := runtime.NumCPU()
+	batches := make([]*catFileBatch, 0, workers)
+
+	for i := 0; i < workers; i++ {
+		objects, err := newCatFileBatch(ctx, path)
+		if err != nil {
+			for _, batch := range batches {
+				_ = batch.Close()
+			}
+
+			return nil, err
+		}
+
+		batches = append(batches, objects)
+	}
+
+	reader.wg.Add(workers)
+
+	for _, objects := range batches {
+		go reader.work(objects)
+	}
+
+	return reader, nil
+}
+
+// Add reads gitFile then publishes it, it returns false if ctx is done.
+func (r *blobReader) Add(gitFile *GitFile) bool {
+	select {
+	case r.blobs <- gitFile:
+		return true
+	case <-r.ctx.Done():
+		return false
+	}
+}
+
+// Close waits for the blobs to be published and stops the git processes.
+func (r *blobReader) Close() {
+	close(r.blobs)
+	r.wg.Wait()
+}
+
+func (r *blobReader) work(objects *catFileBatch) {
+	defer r.wg.Done()
+
+	defer func() {
+		if err := objects.Close(); err != nil {
+			log.Warnln("Error while stopping git cat-file", err)
+		}
+	}()
+
+	for {
+		var gitFile *GitFile
+
+		select {
+		case blob, opened := <-r.blobs:
+			if !opened {
+				return
+			}
+
+			gitFile = blob
+		case <-r.ctx.Done():
+			return
+		}
+
+		var records []*GitFile
+
+		err := objects.Read(gitFile.Sha, func(content io.Reader, size int64) error {
+			var err error
+			records, err = r.read(gitFile, content)
+
+			return err
+		})
+		if err != nil && r.ctx.Err() == nil {
+			log.Warnln("Error while reading", gitFile.Sha, err)
+		}
+
+		if !r.publish(gitFile) {
+			return
+		}
+
+		for _, record := range records {
+			if !r.publish(record) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package srcfingerprint
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// catFileBatch reads objects content from a long running `git cat-file --batch` process.
+type catFileBatch struct {
+	ctx    context.Context
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// newCatFileBatch starts `git cat-file --batch` in the repository at path, the process is killed when ctx is done.
+func newCatFileBatch(ctx context.Context, path string) (*catFileBatch, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	cmd.Dir = path
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &catFileBatch{ctx: ctx, cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// Read calls fn with the content of the object sha.
+// The content is only valid until fn returns, and does not need to be fully read.
+func (c *catFileBatch) Read(sha string, fn func(content io.Reader, size int64) error) error {
+	if _, err := fmt.Fprintln(c.stdin, sha); err != nil {
+		return err
+	}
+
+	// The header is "<sha> <type> <size>" or "<sha> missing"
+	header, err := c.stdout.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return fmt.Errorf("unable to read object %s: %s", sha, strings.TrimSpace(header))
+	}
+
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	content := io.LimitReader(c.stdout, size)
+	fnErr := fn(content, size)
+
+	// Skip what has not been read by fn and the trailing line feed
+	if _, err := io.Copy(io.Discard, content); err != nil {
+		return err
+	}
+
+	if _, err := c.stdout.Discard(1); err != nil {
+		return err
+	}
+
+	return fnErr
+}
+
+// Close stops the git process.
+func (c *catFileBatch) Close() error {
+	if err := c.stdin.Close(); err != nil {
+		return err
+	}
+
+	err := c.cmd.Wait()
+	if c.ctx.Err() != nil {
+		// The process has been killed on cancellation
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,164 @@
+package srcfingerprint
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// binaryDetectionSize is the length of the content searched for a NUL byte to detect binary files, as git does.
+const binaryDetectionSize = 8000
+
+// language describes how to recognize the files of a programming language.
+type language struct {
+	name string
+	// extensions are the lowercase extensions of the files, and filenames the names of the files without extension
+	extensions []string
+	filenames  []string
+	// interpreters are the names of the interpreters found in the shebang of scripts, without version
+	interpreters []string
+	comments     commentStyle
+}
+
+// languages are the detected languages, named as on GitHub.
+var languages = []language{
+	{name: "C", extensions: []string{".c", ".h"}, comments: cCommentStyle},
+	{name: "C++", extensions: []string{".cc", ".cpp", ".cxx", ".hh", ".hpp"}, comments: cCommentStyle},
+	{name: "C#", extensions: []string{".cs"}, comments: cCommentStyle},
+	{name: "CSS", extensions: []string{".css"}, comments: cCommentStyle},
+	{name: "Dart", extensions: []string{".dart"}, comments: cCommentStyle},
+	{
+		name:       "Dockerfile",
+		extensions: []string{".dockerfile"},
+		filenames:  []string{"Dockerfile"},
+		comments:   hashCommentStyle,
+	},
+	{name: "Go", extensions: []string{".go"}, comments: cCommentStyle},
+	{name: "HTML", extensions: []string{".htm", ".html"}, comments: htmlCommentStyle},
+	{name: "Haskell", extensions: []string{".hs"}, interpreters: []string{"runhaskell"}, comments: sqlCommentStyle},
+	{name: "JSON", extensions: []string{".json"}},
+	{name: "Java", extensions: []string{".java"}, comments: cCommentStyle},
+	{
+		name:         "JavaScript",
+		extensions:   []string{".cjs", ".js", ".jsx", ".mjs"},
+		interpreters: []string{"node", "nodejs"},
+		comments:     cCommentStyle,
+	},
+	{name: "Kotlin", extensions: []string{".kt", ".kts"}, comments: cCommentStyle},
+	{name: "Lua", extensions: []string{".lua"}, interpreters: []string{"lua"}, comments: luaCommentStyle},
+	{
+		name:       "Makefile",
+		extensions: []string{".mk"},
+		filenames:  []string{"Makefile", "GNUmakefile"},
+		comments:   hashCommentStyle,
+	},
+	{name: "Markdown", extensions: []string{".md", ".markdown"}},
+	{name: "Objective-C", extensions: []string{".m"}, comments: cCommentStyle},
+	{name: "PHP", extensions: []string{".php"}, interpreters: []string{"php"}, comments: cCommentStyle},
+	{name: "Perl", extensions: []string{".pl", ".pm"}, interpreters: []string{"perl"}, comments: hashCommentStyle},
+	{name: "Python", extensions: []string{".py", ".pyw"}, interpreters: []string{"python"}, comments: pythonCommentStyle},
+	{name: "R", extensions: []string{".r"}, interpreters: []string{"Rscript"}, comments: hashCommentStyle},
+	{
+		name:         "Ruby",
+		extensions:   []string{".rb"},
+		filenames:    []string{"Gemfile", "Rakefile"},
+		interpreters: []string{"ruby"},
+		comments:     hashCommentStyle,
+	},
+	{name: "Rust", extensions: []string{".rs"}, comments: cCommentStyle},
+	{name: "SCSS", extensions: []string{".scss"}, comments: cCommentStyle},
+	{name: "SQL", extensions: []string{".sql"}, comments: sqlCommentStyle},
+	{name: "SVG", extensions: []string{".svg"}, comments: htmlCommentStyle},
+	{name: "Scala", extensions: []string{".scala"}, comments: cCommentStyle},
+	{
+		name:         "Shell",
+		extensions:   []string{".bash", ".sh", ".zsh"},
+		interpreters: []string{"ash", "bash", "dash", "ksh", "sh", "zsh"},
+		comments:     hashCommentStyle,
+	},
+	{name: "Swift", extensions: []string{".swift"}, comments: cCommentStyle},
+	{name: "TOML", extensions: []string{".toml"}, comments: hashCommentStyle},
+	{name: "TypeScript", extensions: []string{".ts", ".tsx"}, interpreters: []string{"ts-node"}, comments: cCommentStyle},
+	{name: "Vue", extensions: []string{".vue"}, comments: htmlCommentStyle},
+	{name: "XML", extensions: []string{".xml"}, comments: htmlCommentStyle},
+	{name: "YAML", extensions: []string{".yaml", ".yml"}, comments: hashCommentStyle},
+}
+
+// isBinary returns true if data contains a NUL byte in its first bytes, as git does.
+func isBinary(data []byte) bool {
+	if len(data) > binaryDetectionSize {
+		data = data[:binaryDetectionSize]
+	}
+
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// detectLanguage returns the language of the file at path given its extension or name,
+// or the shebang at the start of content. It returns an empty string if the language is unknown.
+func detectLanguage(path string, content []byte) string {
+	name := filepath.Base(path)
+	extension := strings.ToLower(filepath.Ext(name))
+
+	for _, language := range languages {
+		if containsString(language.extensions, extension) || containsString(language.filenames, name) {
+			return language.name
+		}
+	}
+
+	interpreter := shebangInterpreter(content)
+	if interpreter == "" {
+		return ""
+	}
+
+	for _, language := range languages {
+		if containsString(language.interpreters, interpreter) {
+			return language.name
+		}
+	}
+
+	return ""
+}
+
+// shebangInterpreter returns the name of the interpreter of the shebang at the start of content, without version,
+// such as python for "#!/usr/bin/env python3".
+func shebangInterpreter(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return ""
+	}
+
+	line, _ := bufio.NewReader(bytes.NewReader(content[2:])).ReadString('\n')
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+
+	// env runs the first of its arguments which is neither an option nor a variable
+	if interpreter == "env" {
+		interpreter = ""
+
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+
+				break
+			}
+		}
+	}
+
+	return strings.TrimRight(interpreter, "0123456789.")
+}
+
+// commentStyleOf returns the comment style of a language, no comments are removed if it is unknown.
+func commentStyleOf(name string) commentStyle {
+	for _, language := range languages {
+		if language.name == name {
+			return language.comments
+		}
+	}
+
+	return commentStyle{}
+}
@@ -0,0 +1,46 @@
+package srcfingerprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClassifyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ClassifyTestSuite) TestIsBinary() {
+	assert.False(suite.T(), isBinary([]byte("package main\n")))
+	assert.False(suite.T(), isBinary(nil))
+	assert.True(suite.T(), isBinary([]byte("PNG\x00\x01")))
+	assert.False(suite.T(), isBinary(append([]byte(strings.Repeat("a", binaryDetectionSize)), 0)))
+}
+
+func (suite *ClassifyTestSuite) TestDetectLanguage() {
+	for _, test := range []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{"main.go", "", "Go"},
+		{"src/App.TSX", "", "TypeScript"},
+		{"docker/Dockerfile", "", "Dockerfile"},
+		{"Makefile", "", "Makefile"},
+		{"bin/deploy", "#!/bin/bash\nset -e\n", "Shell"},
+		{"bin/manage", "#!/usr/bin/env python3.11\n", "Python"},
+		{"bin/serve", "#!/usr/bin/env -S node --no-warnings\n", "JavaScript"},
+		{"script.py", "#!/bin/sh\n", "Python"},
+		{"bin/tool", "#!/usr/bin/tool\n", ""},
+		{"logo.png", "PNG\x00", ""},
+		{"LICENSE", "Apache License\n", ""},
+	} {
+		assert.Equal(suite.T(), test.expected, detectLanguage(test.path, []byte(test.content)), test.path)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	suite.Run(t, new(ClassifyTestSuite))
+}
@@ -47,7 +47,7 @@ func terminateProcessAndChildren(pid int32) error {
 	return nil
 }
 
-func cloneGitRepository(ctx context.Context, destDir, gitRepoURL string) error {
+func cloneGitRepository(ctx context.Context, destDir, gitRepoURL string, env []string) error {
 	var outbuf, errbuf bytes.Buffer
 	// git clone github.com/author/name.git /tmp/workdir/author-name/clone
 	cmd := exec.Command("git", "clone", gitRepoURL, destDir)
@@ -55,6 +55,7 @@ func cloneGitRepository(ctx context.Context, destDir, gitRepoURL string) error {
 	cmd.Stderr = &errbuf
 
 	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, env...)
 
 	go func() {
 		<-ctx.Done()
@@ -70,7 +71,14 @@ func cloneGitRepository(ctx context.Context, destDir, gitRepoURL string) error {
 	if exitError, ok := err.(*exec.ExitError); ok {
 		stderr := strings.TrimSpace(errbuf.String())
 
-		if exitError.ExitCode() == gitExitUnclean {
+		if strings.Contains(stderr, "No space left on device") {
+			log.WithError(err).WithFields(log.Fields{
+				"op":     "gitError",
+				"stderr": stderr,
+			}).Warnf("no space left on device")
+
+			return ErrNoSpaceLeft
+		} else if exitError.ExitCode() == gitExitUnclean {
 			log.WithError(err).WithFields(log.Fields{
 				"op":     "gitError",
 				"stderr": stderr,
@@ -2,22 +2,77 @@ package cloner
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 )
 
 const gitExitUnclean = 128
 
+// ErrNoSpaceLeft is returned when a clone fails because the device is full.
+// The clone can be retried once some space has been freed.
+var ErrNoSpaceLeft = errors.New("no space left on device")
+
 // Cloner represents a cloner of git repository.
 type Cloner interface {
-	CloneRepository(ctx context.Context, url string) (string, error)
+	// CloneRepository clones a repository, credentials are used for HTTP URLs and can be nil.
+	CloneRepository(ctx context.Context, url string, credentials *Credentials) (string, error)
+	// Release frees the resources of a repository cloned by CloneRepository.
+	Release(path string) error
 }
 
 // DiskCloner closes a git repository on disk in a temporary file.
 type DiskCloner struct {
 	BaseDir string
+	// KeepClones disables the removal of cloned repositories, for debugging purposes.
+	KeepClones bool
+	// SSH configures the ssh command used to clone over SSH.
+	SSH SSHOptions
+	// HTTP configures the proxy and TLS verification used to clone over HTTP.
+	HTTP HTTPOptions
+}
+
+// SSHOptions configures the ssh command used by git, independently of the user ssh configuration.
+type SSHOptions struct {
+	// KeyPath is the path of the private key to use.
+	KeyPath string
+	// KnownHostsPath is the path of the known_hosts file to use.
+	KnownHostsPath string
+	// StrictHostKeyChecking is the value of the StrictHostKeyChecking ssh option: "yes", "no" or "accept-new".
+	StrictHostKeyChecking string
+}
+
+// command returns the value of GIT_SSH_COMMAND, or an empty string if no option is set.
+func (o SSHOptions) command() string {
+	args := make([]string, 0)
+
+	if o.KeyPath != "" {
+		args = append(args, "-i", shellQuote(o.KeyPath), "-o", "IdentitiesOnly=yes")
+	}
+
+	if o.KnownHostsPath != "" {
+		args = append(args, "-o", shellQuote("UserKnownHostsFile="+o.KnownHostsPath))
+	}
+
+	if o.StrictHostKeyChecking != "" {
+		args = append(args, "-o", shellQuote("StrictHostKeyChecking="+o.StrictHostKeyChecking))
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+
+	// Ignore the user configuration file so the behavior does not depend on the home directory
+	return strings.Join(append([]string{"ssh", "-F", "/dev/null"}, args...), " ")
+}
+
+// shellQuote quotes s for a POSIX shell, as git runs GIT_SSH_COMMAND through a shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // NewDiskCloner creates a new DiskCloner.
@@ -49,13 +104,33 @@ func NewDiskCloner(baseDir string) *DiskCloner {
 }
 
 // CloneRepository clones a git repository given its information.
-func (d *DiskCloner) CloneRepository(ctx context.Context, url string) (string, error) {
+func (d *DiskCloner) CloneRepository(ctx context.Context, url string, credentials *Credentials) (string, error) {
+	config := d.HTTP.config()
+
+	if credentials != nil {
+		credentialsEntries, err := credentialsConfig(url, credentials)
+		if err != nil {
+			return "", err
+		}
+
+		config = append(config, credentialsEntries...)
+	}
+
+	env := gitConfigEnv(config)
+	if sshCommand := d.SSH.command(); sshCommand != "" {
+		env = append(env, "GIT_SSH_COMMAND="+sshCommand)
+	}
+
 	tmpDir, err := os.MkdirTemp(d.BaseDir, "srcfingerprint-")
 	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			return "", ErrNoSpaceLeft
+		}
+
 		return "", err
 	}
 
-	if err := cloneGitRepository(ctx, tmpDir, url); err != nil {
+	if err := cloneGitRepository(ctx, tmpDir, url, env); err != nil {
 		os.RemoveAll(tmpDir)
 
 		return "", err
@@ -63,3 +138,20 @@ func (d *DiskCloner) CloneRepository(ctx context.Context, url string) (string, e
 
 	return tmpDir, nil
 }
+
+// Release removes a directory created by CloneRepository, unless KeepClones is set.
+func (d *DiskCloner) Release(path string) error {
+	if d.KeepClones {
+		log.Infof("Keeping cloned directory %s", path)
+
+		return nil
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+
+	log.Infof("Correctly removed cloned directory %s", path)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cloner
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClonerTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ClonerTestSuite) TestSSHCommand() {
+	assert.Equal(suite.T(), "", SSHOptions{}.command())
+	assert.Equal(suite.T(),
+		"ssh -F /dev/null -i '/home/user/.ssh/id_ed25519' -o IdentitiesOnly=yes "+
+			"-o 'UserKnownHostsFile=/etc/ssh/known_hosts' -o 'StrictHostKeyChecking=accept-new'",
+		SSHOptions{
+			KeyPath:               "/home/user/.ssh/id_ed25519",
+			KnownHostsPath:        "/etc/ssh/known_hosts",
+			StrictHostKeyChecking: "accept-new",
+		}.command())
+	assert.Equal(suite.T(),
+		`ssh -F /dev/null -i '/keys/it'\''s a key' -o IdentitiesOnly=yes`,
+		SSHOptions{KeyPath: "/keys/it's a key"}.command())
+}
+
+func (suite *ClonerTestSuite) TestSSHCommandShellArguments() {
+	options := SSHOptions{KeyPath: "/keys/it's $HOME `key`", KnownHostsPath: "/hosts/'known' hosts"}
+
+	// git runs GIT_SSH_COMMAND through a shell, which must give back the paths unchanged
+	output, err := exec.Command("sh", "-c", "printf '%s\\n' "+strings.TrimPrefix(options.command(), "ssh ")).Output()
+	if !assert.NoError(suite.T(), err) {
+		return
+	}
+
+	assert.Equal(suite.T(),
+		[]string{
+			"-F", "/dev/null", "-i", "/keys/it's $HOME `key`", "-o", "IdentitiesOnly=yes",
+			"-o", "UserKnownHostsFile=/hosts/'known' hosts",
+		},
+		strings.Split(strings.TrimSuffix(string(output), "\n"), "\n"))
+}
+
+func TestCloner(t *testing.T) {
+	suite.Run(t, new(ClonerTestSuite))
+}
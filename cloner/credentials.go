@@ -0,0 +1,62 @@
+package cloner
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Credentials authenticate git over HTTP.
+// They are given to git through its environment, so they appear neither in the process list nor in the clone.
+type Credentials struct {
+	// Authorization is the value of the Authorization header sent by git.
+	Authorization string
+}
+
+// BasicAuth creates Credentials for a basic authentication.
+func BasicAuth(user, password string) *Credentials {
+	return &Credentials{
+		Authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password)),
+	}
+}
+
+// BearerAuth creates Credentials for a bearer token authentication.
+func BearerAuth(token string) *Credentials {
+	return &Credentials{Authorization: "Bearer " + token}
+}
+
+// gitConfigEntry is a git configuration variable.
+type gitConfigEntry struct {
+	key   string
+	value string
+}
+
+// credentialsConfig returns the configuration for git to authenticate on the host of repositoryURL only.
+func credentialsConfig(repositoryURL string, credentials *Credentials) ([]gitConfigEntry, error) {
+	parsedURL, err := url.Parse(repositoryURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return nil, errors.New("credentials can only be used with an HTTP URL")
+	}
+
+	return []gitConfigEntry{{
+		key:   fmt.Sprintf("http.%s://%s/.extraHeader", parsedURL.Scheme, parsedURL.Host),
+		value: "Authorization: " + credentials.Authorization,
+	}}, nil
+}
+
+// gitConfigEnv returns the environment variables setting the configuration entries for git.
+// This requires git 2.31 or later.
+func gitConfigEnv(entries []gitConfigEntry) []string {
+	env := []string{"GIT_CONFIG_COUNT=" + strconv.Itoa(len(entries))}
+
+	for i, entry := range entries {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, entry.key),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, entry.value),
+		)
+	}
+
+	return env
+}
@@ -0,0 +1,31 @@
+package cloner
+
+// HTTPOptions configures the HTTP connections of git, independently of the user git configuration.
+// The providers apply the same options to their API clients.
+type HTTPOptions struct {
+	// Proxy is the URL of the HTTP proxy. If empty, the proxy environment variables are used.
+	Proxy string
+	// CABundle is the path of a PEM file of the certificate authorities to trust, instead of the system ones.
+	CABundle string
+	// InsecureSkipVerify disables the verification of the TLS certificates.
+	InsecureSkipVerify bool
+}
+
+// config returns the git configuration entries applying the options.
+func (o HTTPOptions) config() []gitConfigEntry {
+	config := make([]gitConfigEntry, 0)
+
+	if o.Proxy != "" {
+		config = append(config, gitConfigEntry{key: "http.proxy", value: o.Proxy})
+	}
+
+	if o.CABundle != "" {
+		config = append(config, gitConfigEntry{key: "http.sslCAInfo", value: o.CABundle})
+	}
+
+	if o.InsecureSkipVerify {
+		config = append(config, gitConfigEntry{key: "http.sslVerify", value: "false"})
+	}
+
+	return config
+}
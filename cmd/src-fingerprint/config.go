@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"srcfingerprint/provider"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the content of a configuration file, in YAML or JSON, describing the sources to collect.
+//
+//	sources:
+//	  - name: github-enterprise
+//	    provider: github
+//	    provider_url: https://github.example.com/api/v3/
+//	    objects: [my-org]
+//	    include_forked_repos: true
+//	    token_env: GHE_TOKEN
+type configFile struct {
+	Sources []sourceConfig `yaml:"sources"`
+}
+
+// sourceConfig is a source of repositories: a provider and the objects to collect from it.
+type sourceConfig struct {
+	// Name is the value of the source field of the records, it defaults to the provider.
+	Name        string   `yaml:"name"`
+	Provider    string   `yaml:"provider"`
+	ProviderURL string   `yaml:"provider_url"`
+	Objects     []string `yaml:"objects"`
+
+	IncludeForkedRepos   bool     `yaml:"include_forked_repos"`
+	IncludeArchivedRepos bool     `yaml:"include_archived_repos"`
+	IncludePublicRepos   bool     `yaml:"include_public_repos"`
+	AllOrgs              bool     `yaml:"all_orgs"`
+	IncludeTopics        []string `yaml:"include_topics"`
+	ExcludeTopics        []string `yaml:"exclude_topics"`
+	IncludeLanguages     []string `yaml:"include_languages"`
+	ExcludeLanguages     []string `yaml:"exclude_languages"`
+	SSHCloning           bool     `yaml:"ssh_cloning"`
+	RepositoryName       string   `yaml:"repo_name"`
+	RepositoryIsPrivate  bool     `yaml:"repo_is_private"`
+
+	// Credentials of the source. If not set, the credentials file and then --token are used.
+	credentialsSource `yaml:",inline"`
+}
+
+func loadConfigFile(path string) ([]sourceConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	var file configFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("could not parse config file: %w", err)
+	}
+
+	for i := range file.Sources {
+		if file.Sources[i].Provider == "" {
+			return nil, fmt.Errorf("config file: source %d has no provider", i)
+		}
+
+		if file.Sources[i].Name == "" {
+			file.Sources[i].Name = file.Sources[i].Provider
+		}
+	}
+
+	return file.Sources, nil
+}
+
+// providerOptions returns the options of the provider of the source.
+func (source *sourceConfig) providerOptions() provider.Options {
+	return provider.Options{
+		IncludeForkedRepos:   source.IncludeForkedRepos,
+		IncludeArchivedRepos: source.IncludeArchivedRepos,
+		IncludePublicRepos:   source.IncludePublicRepos,
+		AllOrgs:              source.AllOrgs,
+		IncludeTopics:        source.IncludeTopics,
+		ExcludeTopics:        source.ExcludeTopics,
+		IncludeLanguages:     source.IncludeLanguages,
+		ExcludeLanguages:     source.ExcludeLanguages,
+		BaseURL:              source.ProviderURL,
+		RepositoryName:       source.RepositoryName,
+		RespositoryIsPrivate: source.RepositoryIsPrivate,
+		SSHCloning:           source.SSHCloning,
+	}
+}
+
+// objectCredentials returns the credentials of an object of the source: the credentials of the source,
+// else the credentials of the matching entry of the credentials file, else defaultCredentials.
+func (source *sourceConfig) objectCredentials(
+	entries []credentialsEntry,
+	object string,
+	defaultCredentials credentials) (credentials, error) {
+	if source.credentialsSource.isSet() {
+		return source.credentialsSource.resolve()
+	}
+
+	if entry := findCredentialsEntry(entries, source.Provider, source.ProviderURL, object); entry != nil {
+		return entry.resolve()
+	}
+
+	return defaultCredentials, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"srcfingerprint/provider"
+	"srcfingerprint/redact"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// credentialsFile is the content of a credentials file, in YAML or JSON.
+//
+//	credentials:
+//	  - provider: github
+//	    base_url: https://github.example.com/api/v3/
+//	    object: my-org
+//	    token_env: MY_ORG_TOKEN
+type credentialsFile struct {
+	Credentials []credentialsEntry `yaml:"credentials"`
+}
+
+// credentialsEntry maps a provider, a base URL and an object to a token or a credential source.
+// An empty base URL or object matches any base URL or object.
+type credentialsEntry struct {
+	Provider string `yaml:"provider"`
+	BaseURL  string `yaml:"base_url"`
+	Object   string `yaml:"object"`
+
+	credentialsSource `yaml:",inline"`
+}
+
+// credentialsSource is a token or a credential source.
+type credentialsSource struct {
+	// Token is the token itself.
+	Token string `yaml:"token"`
+	// TokenEnv is the name of the environment variable containing the token.
+	TokenEnv string `yaml:"token_env"`
+	// TokenFile is the path of the file containing the token.
+	TokenFile string `yaml:"token_file"`
+	// GitHubApp authenticates as a GitHub App installation.
+	GitHubApp *githubAppEntry `yaml:"github_app"`
+}
+
+type githubAppEntry struct {
+	AppID          int64  `yaml:"app_id"`
+	InstallationID int64  `yaml:"installation_id"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+// credentials are the resolved credentials for a provider.
+type credentials struct {
+	token     string
+	githubApp *provider.GitHubAppCredentials
+}
+
+func loadCredentialsFile(path string) ([]credentialsEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read credentials file: %w", err)
+	}
+
+	var file credentialsFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("could not parse credentials file: %w", err)
+	}
+
+	for i, entry := range file.Credentials {
+		if entry.Provider == "" {
+			return nil, fmt.Errorf("credentials file: entry %d has no provider", i)
+		}
+	}
+
+	return file.Credentials, nil
+}
+
+func sameBaseURL(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "/"), strings.TrimSuffix(b, "/"))
+}
+
+// findCredentialsEntry returns the most specific entry matching a provider, a base URL and an object,
+// nil if none matches. An entry for the object takes precedence over an entry for the base URL.
+func findCredentialsEntry(entries []credentialsEntry, providerStr, baseURL, object string) *credentialsEntry {
+	var (
+		found     *credentialsEntry
+		bestScore = -1
+	)
+
+	for i := range entries {
+		entry := &entries[i]
+		score := 0
+
+		if entry.Provider != providerStr {
+			continue
+		}
+
+		if entry.BaseURL != "" {
+			if !sameBaseURL(entry.BaseURL, baseURL) {
+				continue
+			}
+
+			score++
+		}
+
+		if entry.Object != "" {
+			if !strings.EqualFold(entry.Object, object) {
+				continue
+			}
+
+			score += 2
+		}
+
+		if score > bestScore {
+			found, bestScore = entry, score
+		}
+	}
+
+	return found
+}
+
+// isSet returns true if any credentials are set.
+func (source *credentialsSource) isSet() bool {
+	return source.Token != "" || source.TokenEnv != "" || source.TokenFile != "" || source.GitHubApp != nil
+}
+
+// resolve reads the credentials from their source.
+func (source *credentialsSource) resolve() (credentials, error) {
+	if source.GitHubApp != nil {
+		privateKey, err := os.ReadFile(source.GitHubApp.PrivateKeyFile)
+		if err != nil {
+			return credentials{}, fmt.Errorf("could not read GitHub App private key: %w", err)
+		}
+
+		return credentials{githubApp: &provider.GitHubAppCredentials{
+			AppID:          source.GitHubApp.AppID,
+			InstallationID: source.GitHubApp.InstallationID,
+			PrivateKey:     privateKey,
+		}}, nil
+	}
+
+	token := source.Token
+
+	switch {
+	case source.TokenEnv != "":
+		var found bool
+		if token, found = os.LookupEnv(source.TokenEnv); !found {
+			return credentials{}, fmt.Errorf("environment variable %s is not set", source.TokenEnv)
+		}
+	case source.TokenFile != "":
+		content, err := os.ReadFile(source.TokenFile)
+		if err != nil {
+			return credentials{}, fmt.Errorf("could not read token file: %w", err)
+		}
+
+		token = strings.TrimSpace(string(content))
+	case token == "":
+		return credentials{}, errors.New("no token, token_env, token_file or github_app")
+	}
+
+	redact.AddSecret(token)
+
+	return credentials{token: token}, nil
+}
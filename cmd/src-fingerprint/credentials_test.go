@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CredentialsTestSuite struct {
+	suite.Suite
+}
+
+// credentialsTestEntries are the entries of a credentials file, their token identifies them.
+var credentialsTestEntries = []credentialsEntry{
+	{Provider: "github", credentialsSource: credentialsSource{Token: "github"}},
+	{Provider: "github", credentialsSource: credentialsSource{Token: "github-duplicate"}},
+	{
+		Provider:          "github",
+		BaseURL:           "https://github.example.com/api/v3/",
+		credentialsSource: credentialsSource{Token: "github-enterprise"},
+	},
+	{Provider: "github", Object: "My-Org", credentialsSource: credentialsSource{Token: "my-org"}},
+	{
+		Provider:          "github",
+		BaseURL:           "https://GITHUB.example.com/api/v3",
+		Object:            "my-org",
+		credentialsSource: credentialsSource{Token: "github-enterprise-my-org"},
+	},
+	{Provider: "github", Object: "tied-org", credentialsSource: credentialsSource{Token: "tied-org"}},
+	{Provider: "github", Object: "tied-org", credentialsSource: credentialsSource{Token: "tied-org-duplicate"}},
+	{Provider: "gitlab", BaseURL: "https://gitlab.example.com", credentialsSource: credentialsSource{Token: "gitlab"}},
+}
+
+func (suite *CredentialsTestSuite) TestFindCredentialsEntry() {
+	tests := []struct {
+		name     string
+		provider string
+		baseURL  string
+		object   string
+		expected string
+	}{
+		{"any base URL and object", "github", "", "other-org", "github"},
+		{"base URL", "github", "https://github.example.com/api/v3/", "other-org", "github-enterprise"},
+		{"base URL without trailing slash", "github", "https://github.example.com/api/v3", "other-org", "github-enterprise"},
+		{"object over base URL", "github", "https://github.com/", "my-org", "my-org"},
+		{"object case folding", "github", "", "MY-ORG", "my-org"},
+		{"base URL and object", "github", "https://github.example.com/api/v3/", "My-Org", "github-enterprise-my-org"},
+		{"tie", "github", "", "tied-org", "tied-org"},
+		{"other base URL", "gitlab", "https://gitlab.com", "group", ""},
+		{"other provider", "bitbucket", "", "project", ""},
+	}
+
+	for _, test := range tests {
+		entry := findCredentialsEntry(credentialsTestEntries, test.provider, test.baseURL, test.object)
+
+		if test.expected == "" {
+			assert.Nil(suite.T(), entry, test.name)
+
+			continue
+		}
+
+		if assert.NotNil(suite.T(), entry, test.name) {
+			assert.Equal(suite.T(), test.expected, entry.Token, test.name)
+		}
+	}
+}
+
+func (suite *CredentialsTestSuite) TestObjectCredentials() {
+	os.Setenv("SRC_FINGERPRINT_TEST_TOKEN", "env")
+	defer os.Unsetenv("SRC_FINGERPRINT_TEST_TOKEN")
+
+	defaultCredentials := credentials{token: "default"}
+
+	tests := []struct {
+		name     string
+		source   sourceConfig
+		object   string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "source credentials",
+			source:   sourceConfig{Provider: "github", credentialsSource: credentialsSource{Token: "source"}},
+			object:   "my-org",
+			expected: "source",
+		},
+		{
+			name: "source token_env",
+			source: sourceConfig{
+				Provider:          "github",
+				credentialsSource: credentialsSource{TokenEnv: "SRC_FINGERPRINT_TEST_TOKEN"},
+			},
+			object:   "my-org",
+			expected: "env",
+		},
+		{
+			name: "missing source token_env",
+			source: sourceConfig{
+				Provider:          "github",
+				credentialsSource: credentialsSource{TokenEnv: "SRC_FINGERPRINT_TEST_MISSING_TOKEN"},
+			},
+			object: "my-org",
+			err:    true,
+		},
+		{
+			name:     "credentials file",
+			source:   sourceConfig{Provider: "github"},
+			object:   "my-org",
+			expected: "my-org",
+		},
+		{
+			name:   "missing token_env in the credentials file",
+			source: sourceConfig{Provider: "bitbucket", ProviderURL: "https://bitbucket.example.com/rest/api/1.0/"},
+			object: "project",
+			err:    true,
+		},
+		{
+			name:     "default credentials",
+			source:   sourceConfig{Provider: "gitlab", ProviderURL: "https://gitlab.com"},
+			object:   "group",
+			expected: "default",
+		},
+	}
+
+	entries := append([]credentialsEntry{{
+		Provider:          "bitbucket",
+		credentialsSource: credentialsSource{TokenEnv: "SRC_FINGERPRINT_TEST_MISSING_TOKEN"},
+	}}, credentialsTestEntries...)
+
+	for _, test := range tests {
+		creds, err := test.source.objectCredentials(entries, test.object, defaultCredentials)
+
+		if test.err {
+			assert.Error(suite.T(), err, test.name)
+
+			continue
+		}
+
+		if assert.NoError(suite.T(), err, test.name) {
+			assert.Equal(suite.T(), test.expected, creds.token, test.name)
+		}
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	suite.Run(t, new(CredentialsTestSuite))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"srcfingerprint/cloner"
 	"srcfingerprint/exporter"
 	"srcfingerprint/provider"
+	"srcfingerprint/redact"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -21,14 +23,25 @@ var date = "unknown"
 
 const MaxPipelineEvents = 100
 
-func getProvider(providerStr string, token string, providerOptions provider.Options) (provider.Provider, error) {
+func getProvider(
+	providerStr string,
+	providerCredentials credentials,
+	providerOptions provider.Options) (provider.Provider, error) {
+	if providerCredentials.githubApp != nil && providerStr != "github" {
+		return nil, fmt.Errorf("GitHub App authentication is not available for provider: %s", providerStr)
+	}
+
 	switch providerStr {
 	case "github":
-		return provider.NewGitHubProvider(token, providerOptions), nil
+		if providerCredentials.githubApp != nil {
+			return provider.NewGitHubAppProvider(*providerCredentials.githubApp, providerOptions)
+		}
+
+		return provider.NewGitHubProvider(providerCredentials.token, providerOptions), nil
 	case "gitlab":
-		return provider.NewGitLabProvider(token, providerOptions), nil
+		return provider.NewGitLabProvider(providerCredentials.token, providerOptions), nil
 	case "bitbucket":
-		return provider.NewBitbucketProvider(token, providerOptions), nil
+		return provider.NewBitbucketProvider(providerCredentials.token, providerOptions)
 	case "repository":
 		return provider.NewGenericProvider(providerOptions), nil
 	default:
@@ -36,16 +49,94 @@ func getProvider(providerStr string, token string, providerOptions provider.Opti
 	}
 }
 
-func getExporter(exporterStr string, output io.WriteCloser) (exporter.Exporter, error) {
+// getSources returns the sources to collect: the source given by --provider and the sources of the config file.
+func getSources(c *cli.Context) ([]sourceConfig, error) {
+	sources := make([]sourceConfig, 0)
+
+	if c.String("provider") != "" {
+		sources = append(sources, sourceConfig{
+			Provider:             c.String("provider"),
+			ProviderURL:          c.String("provider-url"),
+			Objects:              c.StringSlice("object"),
+			IncludeForkedRepos:   c.Bool("include-forked-repos"),
+			IncludeArchivedRepos: c.Bool("include-archived-repos"),
+			IncludePublicRepos:   c.Bool("include-public-repos"),
+			AllOrgs:              c.Bool("all-orgs"),
+			IncludeTopics:        c.StringSlice("include-topic"),
+			ExcludeTopics:        c.StringSlice("exclude-topic"),
+			IncludeLanguages:     c.StringSlice("include-language"),
+			ExcludeLanguages:     c.StringSlice("exclude-language"),
+			SSHCloning:           c.Bool("ssh-cloning"),
+			RepositoryName:       c.String("repo-name"),
+			RepositoryIsPrivate:  c.Bool("repo-is-private"),
+		})
+	}
+
+	if configPath := c.String("config"); configPath != "" {
+		configSources, err := loadConfigFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// The source given by --provider is only named when combined with a config file,
+		// so the records of a single source are unchanged
+		if len(sources) > 0 {
+			sources[0].Name = sources[0].Provider
+		}
+
+		sources = append(sources, configSources...)
+	}
+
+	if len(sources) == 0 {
+		return nil, errors.New("either --provider or --config is required")
+	}
+
+	for _, source := range sources {
+		if source.AllOrgs && (source.Provider != "github" || len(source.Objects) > 0) {
+			return nil, fmt.Errorf("all orgs can only be collected with the github provider and no object, not %s %v",
+				source.Provider, source.Objects)
+		}
+	}
+
+	return sources, nil
+}
+
+// getGitHubAppCredentials returns the GitHub App credentials given on the command line, nil if there are none.
+func getGitHubAppCredentials(c *cli.Context) (*provider.GitHubAppCredentials, error) {
+	if c.Int64("github-app-id") == 0 {
+		return nil, nil
+	}
+
+	if c.Int64("github-app-installation-id") == 0 || c.String("github-app-private-key") == "" {
+		return nil, errors.New("--github-app-installation-id and --github-app-private-key are required with --github-app-id")
+	}
+
+	privateKey, err := os.ReadFile(c.String("github-app-private-key"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read GitHub App private key: %w", err)
+	}
+
+	return &provider.GitHubAppCredentials{
+		AppID:          c.Int64("github-app-id"),
+		InstallationID: c.Int64("github-app-installation-id"),
+		PrivateKey:     privateKey,
+	}, nil
+}
+
+func getExporter(exporterStr string, output io.WriteCloser, schemaVersion int) (exporter.Exporter, error) {
+	if schemaVersion != exporter.SchemaVersion1 && schemaVersion != exporter.SchemaVersion2 {
+		return nil, fmt.Errorf("invalid schema version: %d", schemaVersion)
+	}
+
 	switch exporterStr {
 	case "json":
-		return exporter.NewJSONExporter(output), nil
+		return exporter.NewJSONExporter(output, schemaVersion), nil
 	case "gzip-json":
-		return exporter.NewGzipJSONExporter(output), nil
+		return exporter.NewGzipJSONExporter(output, schemaVersion), nil
 	case "jsonl":
-		return exporter.NewJSONLExporter(output), nil
+		return exporter.NewJSONLExporter(output, schemaVersion), nil
 	case "gzip-jsonl":
-		return exporter.NewGzipJSONLExporter(output), nil
+		return exporter.NewGzipJSONLExporter(output, schemaVersion), nil
 	default:
 		return nil, fmt.Errorf("invalid export format: %s", exporterStr)
 	}
@@ -97,10 +188,15 @@ func main() {
 				Action: collectAction,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     "provider",
-						Aliases:  []string{"p"},
-						Required: true,
-						Usage:    "Source code provider. options: 'gitlab'/'github'/'bitbucket'/'repository'.",
+						Name:    "provider",
+						Aliases: []string{"p"},
+						Usage: "Source code provider. options: 'gitlab'/'github'/'bitbucket'/'repository'. " +
+							"Required unless --config is set.",
+					},
+					&cli.StringFlag{
+						Name: "config",
+						Usage: "Path of a YAML or JSON file describing several sources to collect, each with a provider, " +
+							"a provider URL, objects and options. Records have a 'source' field with the name of their source.",
 					},
 					&cli.StringFlag{
 						Name:  "provider-url",
@@ -112,10 +208,34 @@ func main() {
 						Usage: "Repository, organization or group to scrape. If not specified all reachable " +
 							"repositories will be collected.",
 					},
+					&cli.StringSliceFlag{
+						Name: "include-topic",
+						Usage: "Only include repositories with this topic. Can be repeated to include several topics. " +
+							"Available for 'github' provider only.",
+					},
+					&cli.StringSliceFlag{
+						Name:  "exclude-topic",
+						Usage: "Exclude repositories with this topic. Can be repeated. Available for 'github' provider only.",
+					},
+					&cli.StringSliceFlag{
+						Name: "include-language",
+						Usage: "Only include repositories with this primary language, such as 'Go'. Can be repeated. " +
+							"Available for 'github' provider only.",
+					},
+					&cli.StringSliceFlag{
+						Name:  "exclude-language",
+						Usage: "Exclude repositories with this primary language. Can be repeated. Available for 'github' provider only.",
+					},
+					&cli.BoolFlag{
+						Name:  "all-orgs",
+						Value: false,
+						Usage: "Collect the repositories of every organization visible to the token, instead of --object. " +
+							"All the organizations of the instance on GitHub Enterprise. Available for 'github' provider only.",
+					},
 					&cli.BoolFlag{
 						Name:  "include-forked-repos",
 						Value: false,
-						Usage: "Include forked repositories. Available for 'github' and 'gitlab' providers.",
+						Usage: "Include forked repositories. Available for 'github', 'gitlab' and 'bitbucket' providers.",
 					},
 					&cli.BoolFlag{
 						Name:  "include-public-repos",
@@ -133,6 +253,11 @@ func main() {
 						Value:   "gzip-jsonl",
 						Usage:   "Export format: 'jsonl'/'gzip-jsonl'/'json'/'gzip-json'.",
 					},
+					&cli.IntFlag{
+						Name:  "schema-version",
+						Value: exporter.SchemaVersion1,
+						Usage: "Schema version of the records: 1, where the size is a string, or 2, where it is a number.",
+					},
 					&cli.StringFlag{
 						Name:    "output",
 						Aliases: []string{"o"},
@@ -144,16 +269,129 @@ func main() {
 						Value: "-",
 						Usage: "Set cloning location for repositories.",
 					},
+					&cli.Int64Flag{
+						Name:  "clone-disk-budget",
+						Value: 0,
+						Usage: "Maximum disk space in MB used by repositories cloned simultaneously (0 for unlimited). " +
+							"Large repositories are held back until enough space is freed.",
+					},
+					&cli.BoolFlag{
+						Name:  "keep-clones",
+						Value: false,
+						Usage: "Do not remove cloned repositories from the clone directory once processed, for debugging.",
+					},
 					&cli.BoolFlag{
 						Name:  "ssh-cloning",
 						Value: false,
 						Usage: "Use ssh to clone repositories. The standard behavior is not guaranteed with this option.",
 					},
+					&cli.StringFlag{
+						Name:  "ssh-key",
+						Usage: "Path of the private key used to clone over SSH, instead of the SSH agent and user configuration.",
+					},
+					&cli.StringFlag{
+						Name:  "ssh-known-hosts",
+						Usage: "Path of the known_hosts file used to clone over SSH.",
+					},
+					&cli.StringFlag{
+						Name:  "ssh-strict-host-key-checking",
+						Usage: "StrictHostKeyChecking SSH option used to clone over SSH: 'yes'/'no'/'accept-new'.",
+					},
+					&cli.StringFlag{
+						Name: "proxy",
+						Usage: "URL of the HTTP proxy used for the API calls and the clones, such as http://proxy:3128. " +
+							"Defaults to the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables.",
+					},
+					&cli.StringFlag{
+						Name: "ca-bundle",
+						Usage: "Path of a PEM file of the certificate authorities trusted for the API calls and the clones, " +
+							"instead of the system ones.",
+					},
+					&cli.BoolFlag{
+						Name:  "insecure-skip-verify",
+						Value: false,
+						Usage: "Do not verify the TLS certificates of the API and of the clones. Insecure.",
+					},
 					&cli.StringFlag{
 						Name:  "after",
 						Value: "",
 						Usage: "Set a commit date after which we want to collect fileshas.",
 					},
+					&cli.StringSliceFlag{
+						Name: "refs",
+						Usage: "Refs whose history is collected: 'all'/'default'/'branches'/'tags' or a pattern such as " +
+							"'refs/heads/release/*'. Can be repeated. Defaults to all the refs.",
+					},
+					&cli.StringSliceFlag{
+						Name: "include-path",
+						Usage: "Only collect the files matching this gitignore pattern, such as 'src/' or '*.go'. " +
+							"Can be repeated.",
+					},
+					&cli.StringSliceFlag{
+						Name:  "exclude-path",
+						Usage: "Do not collect the files matching this gitignore pattern. Can be repeated.",
+					},
+					&cli.BoolFlag{
+						Name:  "exclude-vendored",
+						Value: false,
+						Usage: "Do not collect vendored dependencies, such as 'vendor/', 'node_modules/' or 'third_party/'.",
+					},
+					&cli.Int64Flag{
+						Name:  "min-size",
+						Usage: "Minimum size in bytes of the collected files (0 for no minimum).",
+					},
+					&cli.Int64Flag{
+						Name:  "max-size",
+						Usage: "Maximum size in bytes of the collected files (0 for no maximum).",
+					},
+					&cli.BoolFlag{
+						Name:  "skip-common-files",
+						Value: false,
+						Usage: "Do not collect the files found in countless unrelated repositories, " +
+							"such as empty files, common licenses and common .gitignore files.",
+					},
+					&cli.StringFlag{
+						Name:  "content-hash",
+						Usage: "Add the hash of the content of the files with this algorithm: 'sha256'.",
+					},
+					&cli.BoolFlag{
+						Name:  "winnowing",
+						Value: false,
+						Usage: "Add a record of type 'winnowing' with the fingerprints of the content of each text file, " +
+							"normalized to detect partial copies of code.",
+					},
+					&cli.BoolFlag{
+						Name:  "classify",
+						Value: false,
+						Usage: "Add the is_binary and language fields to the records, detected from the content of the files, " +
+							"their extension and their shebang.",
+					},
+					&cli.BoolFlag{
+						Name:  "head-only",
+						Value: false,
+						Usage: "Only collect the files of the default branch as of its last commit, instead of the whole history.",
+					},
+					&cli.BoolFlag{
+						Name:  "first-seen",
+						Value: false,
+						Usage: "Add the first commit introducing each file, with its author date and author email. " +
+							"This walks the whole history of each repository once.",
+					},
+					&cli.BoolFlag{
+						Name:  "lfs",
+						Value: false,
+						Usage: "Add a record of type 'lfs' with the LFS object id and size for each Git LFS pointer file.",
+					},
+					&cli.BoolFlag{
+						Name:  "submodules",
+						Value: false,
+						Usage: "Add a record of type 'submodule' with the path, commit and URL of each submodule.",
+					},
+					&cli.BoolFlag{
+						Name:  "recurse-submodules",
+						Value: false,
+						Usage: "Also collect the submodules hosted on the same host as their repository. Implies --submodules.",
+					},
 					&cli.StringFlag{
 						Name:  "repo-name",
 						Usage: "Name of the repository to display in outputs if the provider is 'repository'.",
@@ -169,6 +407,26 @@ func main() {
 						Usage:   "Token for vcs access.",
 						EnvVars: []string{"VCS_TOKEN", "GITLAB_TOKEN", "GITHUB_TOKEN"},
 					},
+					&cli.StringFlag{
+						Name: "credentials-file",
+						Usage: "Path of a YAML or JSON file mapping providers, provider URLs and objects to tokens. " +
+							"Objects without matching credentials use --token.",
+					},
+					&cli.Int64Flag{
+						Name:    "github-app-id",
+						Usage:   "ID of the GitHub App to authenticate with, instead of a token. 'github' provider only.",
+						EnvVars: []string{"GITHUB_APP_ID"},
+					},
+					&cli.Int64Flag{
+						Name:    "github-app-installation-id",
+						Usage:   "ID of the installation of the GitHub App to authenticate with.",
+						EnvVars: []string{"GITHUB_APP_INSTALLATION_ID"},
+					},
+					&cli.StringFlag{
+						Name:    "github-app-private-key",
+						Usage:   "Path of the PEM encoded private key of the GitHub App to authenticate with.",
+						EnvVars: []string{"GITHUB_APP_PRIVATE_KEY_PATH"},
+					},
 					&cli.IntFlag{
 						Name:  "cloners",
 						Value: DefaultClonerN,
@@ -178,7 +436,7 @@ func main() {
 						Name:  "limit",
 						Value: DefaultLimit,
 						Usage: "Maximum number of repositories to analyze (0 for unlimited). " +
-							"The limit is applied for independently to each object.",
+							"The limit is applied independently to each object, and to each organization with --all-orgs.",
 					},
 					&cli.DurationFlag{
 						Name:  "timeout",
@@ -202,6 +460,9 @@ func main() {
 }
 
 func beforeAction(c *cli.Context) error {
+	// Mask secrets such as tokens in every log line
+	log.SetFormatter(&redact.Formatter{Formatter: log.StandardLogger().Formatter})
+
 	if c.Bool("debug") {
 		log.SetLevel(log.DebugLevel)
 	} else if c.Bool("verbose") {
@@ -214,6 +475,8 @@ func beforeAction(c *cli.Context) error {
 }
 
 func collectAction(c *cli.Context) error {
+	redact.AddSecret(c.String("token"))
+
 	output := os.Stdout
 	fsOutput := false
 
@@ -237,18 +500,21 @@ func collectAction(c *cli.Context) error {
 		}(output)
 	}
 
-	var srcCloner cloner.Cloner = cloner.NewDiskCloner(c.String("clone-dir"))
-
-	providerOptions := provider.Options{
-		IncludeForkedRepos:   c.Bool("include-forked-repos"),
-		IncludeArchivedRepos: c.Bool("include-archived-repos"),
-		IncludePublicRepos:   c.Bool("include-public-repos"),
-		BaseURL:              c.String("provider-url"),
-		RepositoryName:       c.String("repo-name"),
-		RespositoryIsPrivate: c.Bool("repo-is-private"),
-		SSHCloning:           c.Bool("ssh-cloning"),
+	diskCloner := cloner.NewDiskCloner(c.String("clone-dir"))
+	diskCloner.KeepClones = c.Bool("keep-clones")
+	diskCloner.SSH = cloner.SSHOptions{
+		KeyPath:               c.String("ssh-key"),
+		KnownHostsPath:        c.String("ssh-known-hosts"),
+		StrictHostKeyChecking: c.String("ssh-strict-host-key-checking"),
+	}
+	diskCloner.HTTP = cloner.HTTPOptions{
+		Proxy:              c.String("proxy"),
+		CABundle:           c.String("ca-bundle"),
+		InsecureSkipVerify: c.Bool("insecure-skip-verify"),
 	}
 
+	var srcCloner cloner.Cloner = diskCloner
+
 	defer func() {
 		if r := recover(); r != nil {
 			log.Errorln(r)
@@ -261,37 +527,168 @@ func collectAction(c *cli.Context) error {
 		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
 	}
 
-	srcProvider, err := getProvider(c.String("provider"), c.String("token"), providerOptions)
+	sources, err := getSources(c)
 	if err != nil {
 		log.Errorln(err)
 		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
 	}
 
-	outputExporter, err := getExporter(c.String("export-format"), output)
+	githubApp, err := getGitHubAppCredentials(c)
 	if err != nil {
 		log.Errorln(err)
 		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
 	}
 
+	if githubApp != nil && c.String("provider") != "" && c.String("provider") != "github" {
+		log.Errorf("GitHub App authentication is not available for provider: %s\n", c.String("provider"))
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
+	var credentialsEntries []credentialsEntry
+
+	if credentialsPath := c.String("credentials-file"); credentialsPath != "" {
+		if credentialsEntries, err = loadCredentialsFile(credentialsPath); err != nil {
+			log.Errorln(err)
+			cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+		}
+	}
+
+	outputExporter, err := getExporter(c.String("export-format"), output, c.Int("schema-version"))
+	if err != nil {
+		log.Errorln(err)
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
+	switch c.String("ssh-strict-host-key-checking") {
+	case "", "yes", "no", "accept-new":
+	default:
+		log.Errorln("--ssh-strict-host-key-checking must be 'yes', 'no' or 'accept-new'")
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
+	if sshKey := c.String("ssh-key"); sshKey != "" {
+		if _, err := os.Stat(sshKey); err != nil {
+			log.Errorf("--ssh-key: %v\n", err)
+			cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+		}
+	}
+
+	transport, err := provider.NewHTTPTransport(diskCloner.HTTP)
+	if err != nil {
+		log.Errorln(err)
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
+	if diskCloner.HTTP.InsecureSkipVerify {
+		log.Warnln("TLS certificates are not verified")
+	}
+
+	if err := srcfingerprint.ValidateRefs(c.StringSlice("refs")); err != nil {
+		log.Errorf("--refs: %v\n", err)
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
 	if c.Int("pool") == 0 {
 		log.Errorln("--pool must be non-null")
 		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
 	}
 
+	if c.Int64("min-size") < 0 || c.Int64("max-size") < 0 {
+		log.Errorln("--min-size and --max-size must be positive")
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
+	if err := srcfingerprint.ValidateContentHash(c.String("content-hash")); err != nil {
+		log.Errorf("--content-hash: %v\n", err)
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
+	if c.Int64("clone-disk-budget") < 0 {
+		log.Errorln("--clone-disk-budget must be positive")
+		cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+	}
+
 	pipeline := srcfingerprint.Pipeline{
-		Provider:     srcProvider,
 		Cloner:       srcCloner,
 		Analyzer:     &srcfingerprint.Analyzer{},
 		ClonersCount: c.Int("cloners"),
+		ExtractorOptions: srcfingerprint.ExtractorOptions{
+			LFS:             c.Bool("lfs"),
+			Submodules:      c.Bool("submodules") || c.Bool("recurse-submodules"),
+			FirstSeen:       c.Bool("first-seen"),
+			HeadOnly:        c.Bool("head-only"),
+			Refs:            c.StringSlice("refs"),
+			IncludePaths:    c.StringSlice("include-path"),
+			ExcludePaths:    c.StringSlice("exclude-path"),
+			MinSize:         c.Int64("min-size"),
+			MaxSize:         c.Int64("max-size"),
+			SkipCommonFiles: c.Bool("skip-common-files"),
+			ContentHash:     c.String("content-hash"),
+			Winnowing:       c.Bool("winnowing"),
+			Classify:        c.Bool("classify"),
+		},
+		RecurseSubmodules: c.Bool("recurse-submodules"),
+	}
+
+	if c.Bool("exclude-vendored") {
+		pipeline.ExtractorOptions.ExcludePaths = append(
+			pipeline.ExtractorOptions.ExcludePaths, srcfingerprint.VendoredPaths...)
+	}
+
+	if budget := c.Int64("clone-disk-budget"); budget > 0 {
+		pipeline.DiskBudget = srcfingerprint.NewDiskBudget(budget * 1024)
+	}
+
+	// Each object is extracted with its own provider, authenticated with the credentials of the object
+	extractions := make([]extraction, 0)
+
+	for _, source := range sources {
+		objects := source.Objects
+		// If there is no object, default to an empty object
+		if len(objects) == 0 {
+			objects = []string{""}
+		}
+
+		defaultCredentials := credentials{token: c.String("token")}
+		if source.Provider == "github" {
+			defaultCredentials.githubApp = githubApp
+		}
+
+		for _, object := range objects {
+			objectCredentials, err := source.objectCredentials(credentialsEntries, object, defaultCredentials)
+			if err != nil {
+				log.Errorf("Invalid credentials for object '%s' of %s: %v\n", object, source.Provider, err)
+				cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+			}
+
+			providerOptions := source.providerOptions()
+			providerOptions.Transport = transport
+
+			// With all orgs, the limit is applied to each org rather than to the combined list
+			limit := c.Int("limit")
+			if source.AllOrgs {
+				providerOptions.OrgLimit, limit = limit, 0
+			}
+
+			srcProvider, err := getProvider(source.Provider, objectCredentials, providerOptions)
+			if err != nil {
+				log.Errorln(err)
+				cli.ShowCommandHelpAndExit(c, c.Command.Name, 1)
+			}
+
+			objectPipeline := pipeline
+			objectPipeline.Provider = srcProvider
+			objectPipeline.Source = source.Name
+
+			extractions = append(extractions, extraction{pipeline: &objectPipeline, object: object, limit: limit})
+		}
 	}
 
 	ticker := time.Tick(1 * time.Second)
 
 	eventChannel := runExtract(
-		&pipeline,
-		c.StringSlice("object"),
+		extractions,
 		c.String("after"),
-		c.Int("limit"),
 		timeout,
 		c.Int("pool"),
 	)
@@ -336,9 +733,11 @@ loop:
 			case srcfingerprint.ResultGitFilePipelineEvent:
 				gitFilesCount++
 				err := outputExporter.AddElement(&exporter.ExportGitFile{
-					RepositoryName:    typedEvent.Repository.GetName(),
-					RepositoryPrivate: typedEvent.Repository.GetPrivate(),
-					GitFile:           *typedEvent.GitFile,
+					RepositoryName:       typedEvent.Repository.GetName(),
+					RepositoryPrivate:    typedEvent.Repository.GetPrivate(),
+					RepositoryVisibility: typedEvent.Repository.GetVisibility(),
+					Source:               typedEvent.Source,
+					GitFile:              *typedEvent.GitFile,
 				})
 
 				if err != nil {
@@ -9,25 +9,26 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// extraction is the extraction of an object by a pipeline.
+type extraction struct {
+	pipeline *srcfingerprint.Pipeline
+	object   string
+	// limit is the maximum number of repositories of the object, 0 for unlimited.
+	limit int
+}
+
 type PoolPayload struct {
-	object       string
+	extraction   extraction
 	eventChannel chan srcfingerprint.PipelineEvent
 	waitGroup    *sync.WaitGroup
 }
 
 // Run the extraction in separate goroutine for each objects using a pool of size poolSize.
 func runExtract(
-	pipeline *srcfingerprint.Pipeline,
-	objects []string,
+	extractions []extraction,
 	after string,
-	limit int,
 	timeout time.Duration,
 	poolSize int) chan srcfingerprint.PipelineEvent {
-	// If there is no object, default to an empty object
-	if len(objects) == 0 {
-		objects = []string{""}
-	}
-
 	eventChannel := make(chan srcfingerprint.PipelineEvent, MaxPipelineEvents)
 
 	wg := sync.WaitGroup{}
@@ -39,15 +40,16 @@ func runExtract(
 		}
 
 		defer payload.waitGroup.Done()
-		pipeline.ExtractRepositories(payload.object, after, payload.eventChannel, limit, timeout)
+		payload.extraction.pipeline.ExtractRepositories(
+			payload.extraction.object, after, payload.eventChannel, payload.extraction.limit, timeout)
 
 		return nil
 	})
 
-	for _, object := range objects {
+	for _, extraction := range extractions {
 		wg.Add(1)
 
-		go pool.Process(PoolPayload{object: object, eventChannel: eventChannel, waitGroup: &wg})
+		go pool.Process(PoolPayload{extraction: extraction, eventChannel: eventChannel, waitGroup: &wg})
 	}
 
 	go func() {
@@ -0,0 +1,60 @@
+package srcfingerprint
+
+// commonBlobs are blobs found in countless unrelated repositories, by SHA-1 and SHA-256 object id.
+// Their fingerprints match across every organization and carry no signal.
+// The ids are computed with `git hash-object`, from the texts of /usr/share/common-licenses for the licenses.
+var commonBlobs = map[string]string{
+	// Empty file
+	"e69de29bb2d1d6434b8b29ae775ad8c2e48c5391":                         "empty file",
+	"473a0f4c3be8a93681a267e3b1e9a7dcda1185436fe141f7749120a303721813": "empty file",
+	"8b137891791fe96927ad78e64b0aad7bded08bdc":                         "empty line",
+	"4c0d52d180c61d01ce1a91dec5ee58f0cbe65fd59433aea803ab927965493fd7": "empty line",
+	"40a96afc6ff09d58a702b76e3f7dd412fe975e26":                         "Python coding declaration",
+	"d38da057d36fe53c777d4ca05867010ee5ce9b9dd97c83bafa05a47bc9a825ee": "Python coding declaration",
+
+	// Licenses
+	"d645695673349e3947e8e5ae42332d0ac3164cd7":                         "Apache License 2.0",
+	"81fbaf6d7a24a013d2c8588c428e559917d23403cf3de65ead263df54b05b851": "Apache License 2.0",
+	"d159169d1050894d3ea3b98e1c965c4058208fe1":                         "GNU GPL 2.0",
+	"122a28c8b95d9b8a231fe936073fa94490fdce2b692d03315cccc4667800c232": "GNU GPL 2.0",
+	"f288702d2fa16d3cdf0035b15a9fcbc552cd88e7":                         "GNU GPL 3.0",
+	"a5cec31f6e13655b51bf5fa0822234e1164b0a7602a587a268b3292828124b33": "GNU GPL 3.0",
+	"4362b49151d7b34ef83b3067a8f9c9f877d72a0e":                         "GNU LGPL 2.1",
+	"5f324b06c14e60f885d50a580b9500baa64b93223815d56ed940d583efbc10f6": "GNU LGPL 2.1",
+	"0a041280bd00a9d068f503b8ee7ce35214bd24a1":                         "GNU LGPL 3.0",
+	"67a07b3fa1b3dbfa60cc435408d2d1e0fd025997043fc0451ccb5a69f60fb5fa": "GNU LGPL 3.0",
+	"14e2f777f6c395e7e04ab4aa306bbcc4b0c1120e":                         "Mozilla Public License 2.0",
+	"14f7013a2f4e96cfacd73d0948c4cda870ae38eb779538f17ff6662dcd092022": "Mozilla Public License 2.0",
+	"0e259d42c996742e9e3cba14c677129b2c1b6311":                         "Creative Commons CC0 1.0",
+	"89c734d4e80c1ed393eb2149983fd855383619f4e74eff532c823f2e4d8fe84c": "Creative Commons CC0 1.0",
+
+	// .gitignore
+	"ea8c4bf7f35f6f77f75d92ad8ce8349f6e81ddba":                         ".gitignore: /target",
+	"f0301ff4bace377542a681e2dda2b700dc655001bc2fe7c1c88aeb4321cabe6e": ".gitignore: /target",
+	"eb5a316cbd195d26e3f768c7dd8e1b47299e17f8":                         ".gitignore: target",
+	"7209c4b70c3c455622b1ff86631f9e64a661713f47380a9fc1d31a2147fe2291": ".gitignore: target",
+	"96ef6c0b944e24fc22f51f18136cd62ffd5b0b8f":                         ".gitignore: /target Cargo.lock",
+	"3f24fe7757361284a597b5681a5717c0975d05ba6ec375ba61fd5c72fab1a48d": ".gitignore: /target Cargo.lock",
+	"a9d37c560c6ab8d4afbf47eda643e8c42e857716":                         ".gitignore: target Cargo.lock",
+	"7238a9b806256ca05aed8915e63e0bd0475bea6ab63fa054bbcbe7c209cad62f": ".gitignore: target Cargo.lock",
+	"3c3629e647f5ddf82548912e337bea9826b434af":                         ".gitignore: node_modules",
+	"7445896a4224db25facd738cc3fb13c142343d5e166eb14c1c2415d7e7117d8a": ".gitignore: node_modules",
+	"c2658d7d1b31848c3b71960543cb0368e56cd4c7":                         ".gitignore: node_modules/",
+	"fb579b76181982dbfa08c00bd9b34915b444477376a4b63e0b2f011b166addc1": ".gitignore: node_modules/",
+	"07e6e472cc75fafa944e2a6d4b0f101bc476c060":                         ".gitignore: /node_modules",
+	"e37cfb77860b9d0b45c9083854dcbbef9e15b765584f49adaf2f00340656c669": ".gitignore: /node_modules",
+	"e43b0f988953ae3a84b00331d0ccf5f7d51cb3cf":                         ".gitignore: .DS_Store",
+	"2e165548aef5e301fe249a52018b051d249d2188415d6a5132de64fb4e706431": ".gitignore: .DS_Store",
+	"c18dd8d83ceed1806b50b0aaa46beb7e335fff13":                         ".gitignore: __pycache__/",
+	"1bc5df2e975c04bb8dc3760b7695eb3061a5e4b49db9f6bb1a6dc1bbb395312e": ".gitignore: __pycache__/",
+	"0d20b6487c61e7d1bde93acf4a14b7a89083a16d":                         ".gitignore: *.pyc",
+	"b6d992883c4f41cb68702c479a97ac1b9c7aba8b3917e4c756a03643a4ffe47c": ".gitignore: *.pyc",
+	"485dee64bcfb48793379b200a1afd14e85a8aaf4":                         ".gitignore: .idea",
+	"d51fcbcf2c875ec161596fef30c1d2ebd5c48b4773111068ee0e2357517d4c7b": ".gitignore: .idea",
+	"9f11b755a17d8192c60f61cb17b8902dffbd9f23":                         ".gitignore: .idea/",
+	"803be55b8fc3e1ccad5cdec5a06b524a040a3cddfae976b6bf7c5ec9cf0fdf1a": ".gitignore: .idea/",
+	"4c49bd78f1d08f2bc09fa0bd8191ed38b7dce5e3":                         ".gitignore: .env",
+	"2314da7c343166aa7ba1ce7b9f302c1575af4858d8d29f07bb318fc50107737b": ".gitignore: .env",
+	"397b4a7624e35fa60563a9c03b1213d93f7b6546":                         ".gitignore: *.log",
+	"44128983c66130ab5812799ebfb498c80c9fd7a28caaa7e4e0ae4d4865d8e5a2": ".gitignore: *.log",
+}
@@ -0,0 +1,17 @@
+package srcfingerprint
+
+import (
+	"fmt"
+)
+
+// ContentHashSHA256 sets the plain SHA-256 of the content of each blob, as opposed to its git object id.
+const ContentHashSHA256 = "sha256"
+
+// ValidateContentHash returns an error if contentHash is neither empty nor a supported algorithm.
+func ValidateContentHash(contentHash string) error {
+	if contentHash != "" && contentHash != ContentHashSHA256 {
+		return fmt.Errorf("invalid content hash '%s', expected %s", contentHash, ContentHashSHA256)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package srcfingerprint
+
+import (
+	"context"
+	"sync"
+)
+
+// DiskBudget admits clones against a maximum disk usage.
+// Sizes are expressed in KB, like provider.GitRepository.GetStorageSize.
+type DiskBudget struct {
+	limit int64
+
+	mu       sync.Mutex
+	used     int64
+	inFlight int
+	// released is closed and replaced each time a reservation is released.
+	released chan struct{}
+}
+
+// NewDiskBudget creates a DiskBudget of limit KB.
+func NewDiskBudget(limit int64) *DiskBudget {
+	return &DiskBudget{limit: limit, released: make(chan struct{})}
+}
+
+// Acquire blocks until size KB fit in the budget or ctx is done.
+// A repository larger than the whole budget is only admitted when no other clone is in flight.
+func (b *DiskBudget) Acquire(ctx context.Context, size int64) error {
+	for {
+		b.mu.Lock()
+		if b.inFlight == 0 || b.used+size <= b.limit {
+			b.used += size
+			b.inFlight++
+			b.mu.Unlock()
+
+			return nil
+		}
+
+		released := b.released
+		b.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release frees a reservation made by Acquire.
+func (b *DiskBudget) Release(size int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.release(size)
+}
+
+func (b *DiskBudget) release(size int64) {
+	b.used -= size
+	b.inFlight--
+
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// WaitForRelease releases a reservation made by Acquire and waits for another reservation to be released.
+// It returns false immediately if no other clone is in flight, as waiting would not free any space.
+func (b *DiskBudget) WaitForRelease(ctx context.Context, size int64) bool {
+	b.mu.Lock()
+	b.release(size)
+	othersInFlight := b.inFlight > 0
+	released := b.released
+	b.mu.Unlock()
+
+	if !othersInFlight {
+		return false
+	}
+
+	select {
+	case <-released:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,81 @@
+package srcfingerprint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DiskBudgetTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DiskBudgetTestSuite) TestAcquireWithinBudget() {
+	budget := NewDiskBudget(100)
+
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 40))
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 60))
+}
+
+func (suite *DiskBudgetTestSuite) TestAcquireWaitsForRelease() {
+	budget := NewDiskBudget(100)
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 80))
+
+	acquired := make(chan error)
+
+	go func() {
+		acquired <- budget.Acquire(context.Background(), 50)
+	}()
+
+	select {
+	case <-acquired:
+		suite.T().Fatal("repository should be held back until space is released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	budget.Release(80)
+	assert.Nil(suite.T(), <-acquired)
+}
+
+func (suite *DiskBudgetTestSuite) TestAcquireLargerThanBudgetWhenIdle() {
+	budget := NewDiskBudget(100)
+
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 500))
+}
+
+func (suite *DiskBudgetTestSuite) TestAcquireCanceled() {
+	budget := NewDiskBudget(100)
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 100))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(suite.T(), context.Canceled, budget.Acquire(ctx, 1))
+}
+
+func (suite *DiskBudgetTestSuite) TestWaitForReleaseWhenAlone() {
+	budget := NewDiskBudget(100)
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 10))
+
+	assert.False(suite.T(), budget.WaitForRelease(context.Background(), 10))
+}
+
+func (suite *DiskBudgetTestSuite) TestWaitForReleaseWithOthersInFlight() {
+	budget := NewDiskBudget(100)
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 10))
+	assert.Nil(suite.T(), budget.Acquire(context.Background(), 20))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		budget.Release(20)
+	}()
+
+	assert.True(suite.T(), budget.WaitForRelease(context.Background(), 10))
+}
+
+func TestDiskBudget(t *testing.T) {
+	suite.Run(t, new(DiskBudgetTestSuite))
+}
@@ -5,45 +5,75 @@ import (
 	"encoding/json"
 	"io"
 	"srcfingerprint"
+	"strconv"
+)
+
+const (
+	// SchemaVersion1 is the original schema of the records, where the size is a string.
+	SchemaVersion1 = 1
+	// SchemaVersion2 is the schema of the records where the size is a number.
+	SchemaVersion2 = 2
 )
 
 type ExportGitFile struct {
 	RepositoryName    string `json:"repository_name"` // nolint
 	RepositoryPrivate bool   `json:"private"`
+	// RepositoryVisibility is public, private or internal
+	RepositoryVisibility string `json:"visibility"`
+	// Source is the name of the source of the repository, when collecting several sources
+	Source string `json:"source,omitempty"`
 	srcfingerprint.GitFile
 }
 
+// exportGitFileV1 is a record in the schema version 1, its size hides the size of ExportGitFile.
+type exportGitFileV1 struct {
+	*ExportGitFile
+	Size string `json:"size"`
+}
+
+// record returns the value to encode for gitFile in a schema version.
+func record(gitFile *ExportGitFile, schemaVersion int) interface{} {
+	if schemaVersion == SchemaVersion1 {
+		return &exportGitFileV1{ExportGitFile: gitFile, Size: strconv.FormatInt(gitFile.Size, 10)}
+	}
+
+	return gitFile
+}
+
 type Exporter interface {
 	AddElement(gitFile *ExportGitFile) error
 	Close() error
 }
 
 type JSONExporter struct {
-	elements []*ExportGitFile
-	encoder  *json.Encoder
-	writer   io.WriteCloser
+	elements      []interface{}
+	encoder       *json.Encoder
+	writer        io.WriteCloser
+	schemaVersion int
 }
 
-func NewJSONExporter(output io.WriteCloser) Exporter {
+func NewJSONExporter(output io.WriteCloser, schemaVersion int) Exporter {
 	return &JSONExporter{
-		elements: []*ExportGitFile{},
-		encoder:  json.NewEncoder(output),
-		writer:   output,
+		elements:      []interface{}{},
+		encoder:       json.NewEncoder(output),
+		writer:        output,
+		schemaVersion: schemaVersion,
 	}
 }
 
-func NewGzipJSONExporter(output io.Writer) Exporter {
+func NewGzipJSONExporter(output io.Writer, schemaVersion int) Exporter {
 	compressedWriter := gzip.NewWriter(output)
 
 	return &JSONExporter{
-		elements: []*ExportGitFile{},
-		encoder:  json.NewEncoder(compressedWriter),
-		writer:   compressedWriter,
+		elements:      []interface{}{},
+		encoder:       json.NewEncoder(compressedWriter),
+		writer:        compressedWriter,
+		schemaVersion: schemaVersion,
 	}
 }
 
 func (e *JSONExporter) AddElement(gitFile *ExportGitFile) error {
-	e.elements = append(e.elements, gitFile)
+	e.elements = append(e.elements, record(gitFile, e.schemaVersion))
 
 	return nil
 }
@@ -61,28 +91,31 @@ func (e *JSONExporter) Close() error {
 }
 
 type JSONLExporter struct {
-	encoder *json.Encoder
-	writer  io.WriteCloser
+	encoder       *json.Encoder
+	writer        io.WriteCloser
+	schemaVersion int
 }
 
-func NewJSONLExporter(output io.WriteCloser) Exporter {
+func NewJSONLExporter(output io.WriteCloser, schemaVersion int) Exporter {
 	return &JSONLExporter{
-		encoder: json.NewEncoder(output),
-		writer:  output,
+		encoder:       json.NewEncoder(output),
+		writer:        output,
+		schemaVersion: schemaVersion,
 	}
 }
 
-func NewGzipJSONLExporter(output io.Writer) Exporter {
+func NewGzipJSONLExporter(output io.Writer, schemaVersion int) Exporter {
 	compressedWriter := gzip.NewWriter(output)
 
 	return &JSONLExporter{
-		encoder: json.NewEncoder(compressedWriter),
-		writer:  compressedWriter,
+		encoder:       json.NewEncoder(compressedWriter),
+		writer:        compressedWriter,
+		schemaVersion: schemaVersion,
 	}
 }
 
 func (e *JSONLExporter) AddElement(gitFile *ExportGitFile) error {
-	return e.encoder.Encode(gitFile)
+	return e.encoder.Encode(record(gitFile, e.schemaVersion))
 }
 
 func (e *JSONLExporter) Close() error {
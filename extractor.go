@@ -2,11 +2,15 @@ package srcfingerprint
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"fmt"
-	"os"
+	"hash"
+	"io"
 	"os/exec"
 	"regexp"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -16,33 +20,187 @@ type BaseExtractor interface {
 }
 
 type GitFile struct {
-	Sha      string `json:"sha"`
+	Sha string `json:"sha"`
+	// HashAlgo is the hash algorithm of Sha: HashAlgoSHA1 or HashAlgoSHA256
+	HashAlgo string `json:"hash_algo"`
 	Type     string `json:"type"`
 	Filepath string `json:"filepath"`
-	Size     string `json:"size"`
+	Size     int64  `json:"size"`
+	// URL is the URL of a submodule, as found in .gitmodules
+	URL string `json:"url,omitempty"`
+	// FirstCommit is the first commit introducing the blob, with its author date and email
+	FirstCommit            string `json:"first_commit,omitempty"`
+	FirstCommitDate        string `json:"first_commit_date,omitempty"`
+	FirstCommitAuthorEmail string `json:"first_commit_author_email,omitempty"`
+	// InHead is true if the file is in the tree of HEAD, the default branch of the repository
+	InHead bool `json:"in_head"`
+	// SHA256 is the plain SHA-256 of the content of the file, see ExtractorOptions.ContentHash
+	SHA256 string `json:"sha256,omitempty"`
+	// IsBinary is true if the content of the file has a NUL byte, and Language is the language detected from
+	// the extension of the file or its shebang, see ExtractorOptions.Classify
+	IsBinary *bool  `json:"is_binary,omitempty"`
+	Language string `json:"language,omitempty"`
+	// Fingerprints are the winnowing fingerprints of the file, in the records of type GitFileTypeWinnowing
+	Fingerprints []Fingerprint `json:"fingerprints,omitempty"`
 }
 
-func NewFastExtractor() *FastExtractor {
-	return &FastExtractor{make(chan *GitFile)}
+const (
+	// GitFileTypeLFS is the type of the records emitted for Git LFS objects.
+	GitFileTypeLFS = "lfs"
+	// GitFileTypeSubmodule is the type of the records emitted for submodules.
+	GitFileTypeSubmodule = "submodule"
+	// GitFileTypeWinnowing is the type of the records emitted with the winnowing fingerprints of text files.
+	GitFileTypeWinnowing = "winnowing"
+
+	gitFileTypeTree = "tree"
+)
+
+// ExtractorOptions represents options for the FastExtractor.
+type ExtractorOptions struct {
+	// LFS emits an extra record with the LFS object id and size for each Git LFS pointer file.
+	LFS bool
+	// Submodules emits a record with the path, commit and URL of each submodule found in the trees.
+	Submodules bool
+	// FirstSeen sets the first commit introducing each blob, with its author date and email.
+	FirstSeen bool
+	// HeadOnly only extracts the files of the tree of HEAD, instead of the whole history.
+	HeadOnly bool
+	// Refs selects the refs whose history is extracted: RefsAll, RefsDefault, RefsBranches, RefsTags
+	// or ref patterns. All the refs are extracted if empty. HeadOnly takes precedence over Refs.
+	Refs []string
+	// IncludePaths only extracts the files matching one of these gitignore patterns, if not empty.
+	IncludePaths []string
+	// ExcludePaths does not extract the files matching one of these gitignore patterns, see VendoredPaths.
+	ExcludePaths []string
+	// MinSize and MaxSize are the bounds of the size in bytes of the extracted files, if not 0.
+	MinSize int64
+	MaxSize int64
+	// SkipCommonFiles does not extract the files found in countless unrelated repositories,
+	// such as empty files and licenses.
+	SkipCommonFiles bool
+	// ContentHash sets the SHA-256 of the content of the files if ContentHashSHA256, nothing if empty.
+	ContentHash string
+	// Winnowing emits a record with the winnowing fingerprints of each text file, whose content is normalized
+	// to detect partial copies of code.
+	Winnowing bool
+	// Classify sets whether each file is binary and its language.
+	Classify bool
+}
+
+func NewFastExtractor(options ExtractorOptions) *FastExtractor {
+	return &FastExtractor{make(chan *GitFile), options}
 }
 
 // FastExtractor will directly extract the information without using an Analyzer
 // There are designed to use raw git commands to get what is needed.
 type FastExtractor struct {
 	ChanGitFiles chan *GitFile
+	options      ExtractorOptions
 }
 
-func (fe *FastExtractor) Run(path string, after string) chan *GitFile {
+// Run extracts the files of the repository at path in the background, and emits them on ChanGitFiles.
+// When ctx is done, the extraction stops and ChanGitFiles is closed.
+func (fe *FastExtractor) Run(ctx context.Context, path string, after string) chan *GitFile {
 	log.Infof("Extracting commits from path %s\n", path)
 
-	cmdRevList := "git rev-list --objects --all"
+	revs, err := revisions(path, fe.options.Refs)
+	if err != nil {
+		log.Warnln("Error while selecting the refs", err)
+	}
+
+	if fe.options.HeadOnly {
+		revs = []string{"HEAD"}
+	}
+
+	if len(revs) == 0 {
+		log.Warnf("No ref of %s matches %v\n", path, fe.options.Refs)
+		close(fe.ChanGitFiles)
+
+		return fe.ChanGitFiles
+	}
+
+	// walkArgs select the commits whose trees are extracted
+	walkArgs := append([]string{}, revs...)
+	if fe.options.HeadOnly {
+		walkArgs = append(walkArgs, "--no-walk")
+	}
 
 	if after != "" {
-		cmdRevList = fmt.Sprintf("git rev-list --objects --all --after '%s'", after)
+		walkArgs = append(walkArgs, "--after", after)
+	}
+
+	revListArgs := append([]string{"--objects"}, walkArgs...)
+
+	quotedArgs := make([]string, 0, len(revListArgs))
+	for _, arg := range revListArgs {
+		quotedArgs = append(quotedArgs, shellQuote(arg))
+	}
+
+	cmdRevList := "git rev-list " + strings.Join(quotedArgs, " ")
+
+	hashAlgo, err := objectFormat(path)
+	if err != nil {
+		log.Warnln("Error while detecting the object format, defaulting to", HashAlgoSHA1, err)
+
+		hashAlgo = HashAlgoSHA1
+	}
+
+	inHead, err := headObjects(path)
+	if err != nil {
+		// An empty repository has no HEAD
+		log.Warnln("Error while listing the files of HEAD", err)
 	}
 
-	cmdBase := cmdRevList + "| git cat-file --batch-check='{\"sha\": \"%(objectname)\", \"type\": \"%(objecttype)\", \"filepath\": \"%(rest)\", \"size\": \"%(objectsize)\"}' | grep '\"type\": \"blob\"'" //nolint
-	cmd := exec.Command("bash", "-c", cmdBase)
+	// Trees are needed to find the submodules
+	cmdGrep := "grep '\"type\": \"blob\"'"
+	if fe.options.Submodules {
+		cmdGrep = "grep -E '\"type\": \"(blob|tree)\"'"
+	}
+
+	cmdBase := cmdRevList + "| git cat-file --batch-check='{\"sha\": \"%(objectname)\", \"type\": \"%(objecttype)\", \"filepath\": \"%(rest)\", \"size\": %(objectsize)}' | " + cmdGrep //nolint
+	var (
+		objects    *catFileBatch
+		submodules *submoduleCollector
+		blobs      *blobReader
+	)
+
+	// stopSetup stops the extraction if a process could not be started, which is expected once ctx is done
+	stopSetup := func(err error) chan *GitFile {
+		if ctx.Err() == nil {
+			log.Fatal(err)
+		}
+
+		if blobs != nil {
+			blobs.Close()
+		}
+
+		if objects != nil {
+			_ = objects.Close()
+		}
+
+		close(fe.ChanGitFiles)
+
+		return fe.ChanGitFiles
+	}
+
+	if fe.options.LFS || fe.options.Submodules {
+		if objects, err = newCatFileBatch(ctx, path); err != nil {
+			return stopSetup(err)
+		}
+	}
+
+	if fe.options.Submodules {
+		submodules = newSubmoduleCollector(path)
+	}
+
+	if fe.options.ContentHash != "" || fe.options.Winnowing || fe.options.Classify {
+		publish := func(gitFile *GitFile) bool { return fe.send(ctx, gitFile) }
+		if blobs, err = newBlobReader(ctx, path, fe.readBlob, publish); err != nil {
+			return stopSetup(err)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", cmdBase)
 	cmd.Dir = path
 
 	stdout, err := cmd.StdoutPipe()
@@ -52,14 +210,35 @@ func (fe *FastExtractor) Run(path string, after string) chan *GitFile {
 
 	err = cmd.Start()
 	if err != nil {
-		log.Fatal(err)
+		return stopSetup(err)
 	}
 
 	buf := bufio.NewReader(stdout) // Notice that this is not in a loop
 	num := 0
 
+	paths := newPathFilter(fe.options.IncludePaths, fe.options.ExcludePaths)
+
 	go func() {
-		for {
+		var (
+			commits  map[string]firstSeen
+			allPaths map[string][]string
+			walkErr  error
+		)
+
+		if fe.options.FirstSeen {
+			if commits, walkErr = firstSeenCommits(ctx, path, revs); walkErr != nil && ctx.Err() == nil {
+				log.Warnln("Error while finding the first commit of the files", walkErr)
+			}
+		}
+
+		// A blob is selected if any of its paths matches, not only the path given by git rev-list
+		if paths.IsSet() {
+			if allPaths, walkErr = blobPaths(ctx, path, walkArgs); walkErr != nil && ctx.Err() == nil {
+				log.Warnln("Error while finding the paths of the files", walkErr)
+			}
+		}
+
+		for ctx.Err() == nil {
 			line, _, _ := buf.ReadLine()
 			if len(line) == 0 {
 				log.Infoln("finished reading all files from stdout from git")
@@ -81,20 +260,246 @@ func (fe *FastExtractor) Run(path string, after string) chan *GitFile {
 			if err != nil {
 				// If an error occurs, print a warning and do nothing with the line
 				log.Warnln("Error while parsing", string(line), err)
+			} else if gitFile.Type == gitFileTypeTree {
+				if err := submodules.AddTree(objects, &gitFile); err != nil {
+					log.Warnln("Error while reading submodules", err)
+				}
 			} else {
-				fe.ChanGitFiles <- &gitFile
+				gitFile.HashAlgo = hashAlgo
+
+				// .gitmodules is read even if it is not extracted, to find the submodules
+				if submodules != nil && gitFile.Filepath == gitmodulesPath {
+					if err := submodules.AddGitmodules(gitFile.Sha); err != nil {
+						log.Warnln("Error while reading", gitmodulesPath, err)
+					}
+				}
+
+				filepaths := append([]string{gitFile.Filepath}, allPaths[gitFile.Sha]...)
+				if filepath, selected := paths.Select(filepaths); selected {
+					gitFile.Filepath = filepath
+					fe.publishBlob(ctx, objects, blobs, &gitFile, inHead, commits)
+				}
 			}
 		}
 
 		log.Infof("finished iterating over files, %d file(s) collected.\n", num)
 
-		if err := os.RemoveAll(path); err != nil {
-			log.Errorln("Unable to remove directory ", path)
+		if blobs != nil {
+			blobs.Close()
+		}
+
+		if submodules != nil {
+			for _, submodule := range submodules.Submodules() {
+				if !paths.Match(submodule.Filepath) {
+					continue
+				}
+
+				submodule.HashAlgo = hashAlgo
+				submodule.InHead = inHead[submodule.Sha]
+				fe.send(ctx, submodule)
+			}
+		}
+
+		if objects != nil {
+			if err := objects.Close(); err != nil {
+				log.Warnln("Error while stopping git cat-file", err)
+			}
+		}
+
+		// On cancellation, the git processes of the pipeline are stopped by closing their output.
+		// The error is ignored as grep fails when there is no file.
+		if ctx.Err() != nil {
+			_ = stdout.Close()
 		}
 
-		log.Infof("Correctly removed cloned directory %s", path)
+		_ = cmd.Wait()
+
 		close(fe.ChanGitFiles)
 	}()
 
 	return fe.ChanGitFiles
 }
+
+// publishBlob emits gitFile, once read if blobs is not nil, and the LFS object it references if any.
+func (fe *FastExtractor) publishBlob(
+	ctx context.Context,
+	objects *catFileBatch,
+	blobs *blobReader,
+	gitFile *GitFile,
+	inHead map[string]bool,
+	commits map[string]firstSeen) {
+	gitFile.InHead = inHead[gitFile.Sha]
+
+	if commit, found := commits[gitFile.Sha]; found {
+		gitFile.FirstCommit = commit.Commit
+		gitFile.FirstCommitDate = commit.Date
+		gitFile.FirstCommitAuthorEmail = commit.AuthorEmail
+	}
+
+	if fe.selected(gitFile) {
+		if blobs != nil {
+			blobs.Add(gitFile)
+		} else {
+			fe.send(ctx, gitFile)
+		}
+	}
+
+	// The LFS object is selected independently of its pointer file
+	if fe.options.LFS {
+		fe.extractLFSObject(ctx, objects, gitFile)
+	}
+}
+
+// readBlob sets the content hash and the classification of gitFile, and returns its winnowing fingerprints record,
+// depending on the options.
+func (fe *FastExtractor) readBlob(gitFile *GitFile, content io.Reader) ([]*GitFile, error) {
+	var contentHash hash.Hash
+
+	if fe.options.ContentHash == ContentHashSHA256 {
+		contentHash = sha256.New()
+		content = io.TeeReader(content, contentHash)
+	}
+
+	// Only the start of the content is needed to classify the file, and all of it to fingerprint it
+	winnowing := fe.options.Winnowing && gitFile.Size <= winnowingMaxSize
+	headSize := int64(0)
+
+	switch {
+	case winnowing:
+		headSize = winnowingMaxSize
+	case fe.options.Classify:
+		headSize = binaryDetectionSize
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, headSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if fe.options.Classify {
+		binary := isBinary(data)
+		gitFile.IsBinary = &binary
+		gitFile.Language = detectLanguage(gitFile.Filepath, data)
+	}
+
+	var records []*GitFile
+
+	if winnowing {
+		if record := winnowingRecord(gitFile, data); record != nil {
+			records = append(records, record)
+		}
+	}
+
+	if contentHash != nil {
+		if _, err := io.Copy(io.Discard, content); err != nil {
+			return nil, err
+		}
+
+		gitFile.SHA256 = hex.EncodeToString(contentHash.Sum(nil))
+	}
+
+	return records, nil
+}
+
+// winnowingRecord returns the record with the winnowing fingerprints of gitFile, nil if it is binary or too short.
+func winnowingRecord(gitFile *GitFile, data []byte) *GitFile {
+	if isBinary(data) {
+		return nil
+	}
+
+	fingerprints := winnow(data, commentStyleOf(detectLanguage(gitFile.Filepath, data)))
+	if len(fingerprints) == 0 {
+		return nil
+	}
+
+	return &GitFile{
+		Sha:          gitFile.Sha,
+		HashAlgo:     gitFile.HashAlgo,
+		Type:         GitFileTypeWinnowing,
+		Filepath:     gitFile.Filepath,
+		Size:         gitFile.Size,
+		InHead:       gitFile.InHead,
+		IsBinary:     gitFile.IsBinary,
+		Language:     gitFile.Language,
+		Fingerprints: fingerprints,
+	}
+}
+
+// extractLFSObject emits a record for the LFS object referenced by gitFile if it is a Git LFS pointer file.
+func (fe *FastExtractor) extractLFSObject(ctx context.Context, objects *catFileBatch, gitFile *GitFile) {
+	if gitFile.Size > lfsPointerMaxSize {
+		return
+	}
+
+	var (
+		pointer   lfsPointer
+		isPointer bool
+	)
+
+	err := objects.Read(gitFile.Sha, func(content io.Reader, size int64) error {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+
+		pointer, isPointer = parseLFSPointer(data)
+
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warnln("Error while reading", gitFile.Sha, err)
+		}
+
+		return
+	}
+
+	if !isPointer {
+		return
+	}
+
+	lfsFile := &GitFile{
+		Sha:      pointer.OID,
+		HashAlgo: HashAlgoSHA256,
+		Type:     GitFileTypeLFS,
+		Filepath: gitFile.Filepath,
+		Size:     pointer.Size,
+		InHead:   gitFile.InHead,
+	}
+
+	// The content of an LFS object is not in the repository, only its language is detected
+	if fe.options.Classify {
+		lfsFile.Language = detectLanguage(lfsFile.Filepath, nil)
+	}
+
+	// The object id of an LFS object is the SHA-256 of its content
+	if fe.options.ContentHash == ContentHashSHA256 {
+		lfsFile.SHA256 = pointer.OID
+	}
+
+	if fe.selected(lfsFile) {
+		fe.send(ctx, lfsFile)
+	}
+}
+
+// send emits gitFile, it returns false if ctx is done.
+func (fe *FastExtractor) send(ctx context.Context, gitFile *GitFile) bool {
+	select {
+	case fe.ChanGitFiles <- gitFile:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// selected returns true if the size of gitFile is within the bounds of the options,
+// and gitFile is not a common file if they are skipped.
+func (fe *FastExtractor) selected(gitFile *GitFile) bool {
+	if fe.options.SkipCommonFiles {
+		if _, common := commonBlobs[gitFile.Sha]; common {
+			return false
+		}
+	}
+
+	return gitFile.Size >= fe.options.MinSize && (fe.options.MaxSize == 0 || gitFile.Size <= fe.options.MaxSize)
+}
@@ -0,0 +1,498 @@
+package srcfingerprint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExtractorTestSuite struct {
+	suite.Suite
+}
+
+const lfsPointerContent = `version https://git-lfs.github.com/spec/v1
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Author", "GIT_AUTHOR_EMAIL=author@example.com",
+		"GIT_COMMITTER_NAME=Committer", "GIT_COMMITTER_EMAIL=committer@example.com",
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, output)
+	}
+
+	return string(output)
+}
+
+// createTestGitRepository creates a git repository with a commit for each element of commits.
+func createTestGitRepository(t *testing.T, commits ...map[string]string) string {
+	return createTestGitRepositoryWithFormat(t, HashAlgoSHA1, commits...)
+}
+
+// createTestGitRepositoryWithFormat creates a git repository whose object ids are hashed with objectFormat.
+func createTestGitRepositoryWithFormat(t *testing.T, objectFormat string, commits ...map[string]string) string {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--object-format="+objectFormat)
+
+	for _, files := range commits {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		runGit(t, dir, "add", "--all")
+		runGit(t, dir, "commit", "--quiet", "--message", "commit")
+	}
+
+	return dir
+}
+
+func extractGitFiles(path string, options ExtractorOptions) []GitFile {
+	gitFiles := make([]GitFile, 0)
+	for gitFile := range NewFastExtractor(options).Run(context.Background(), path, "") {
+		gitFiles = append(gitFiles, *gitFile)
+	}
+
+	return gitFiles
+}
+
+func (suite *ExtractorTestSuite) TestRun() {
+	path := createTestGitRepository(suite.T(), map[string]string{"README.md": "hello\n"})
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{})
+
+	assert.Equal(suite.T(), []GitFile{
+		{Sha: "ce013625030ba8dba906f756967f9e9ca394464a", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "README.md", Size: 6, InHead: true},
+	}, gitFiles)
+}
+
+func (suite *ExtractorTestSuite) TestRunLFS() {
+	path := createTestGitRepository(suite.T(), map[string]string{
+		"README.md":   "hello\n",
+		"archive.zip": lfsPointerContent,
+	})
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{LFS: true})
+
+	assert.ElementsMatch(suite.T(), []GitFile{
+		{Sha: "ce013625030ba8dba906f756967f9e9ca394464a", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "README.md", Size: 6, InHead: true},
+		{Sha: "60c8d8ab2adcf57a391163a7eeb0cdb8bf348e44", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "archive.zip", Size: 130, InHead: true},
+		{
+			Sha:      "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+			HashAlgo: HashAlgoSHA256,
+			Type:     GitFileTypeLFS,
+			Filepath: "archive.zip",
+			Size:     12345,
+			InHead:   true,
+		},
+	}, gitFiles)
+}
+
+func (suite *ExtractorTestSuite) TestRunSubmodules() {
+	submodulePath := createTestGitRepository(suite.T(), map[string]string{"main.c": "int main() {}\n"})
+	submoduleCommit := strings.TrimSpace(runGit(suite.T(), submodulePath, "rev-parse", "HEAD"))
+
+	path := createTestGitRepository(suite.T(), map[string]string{"README.md": "hello\n"})
+	runGit(suite.T(), path, "-c", "protocol.file.allow=always", "submodule", "add", "--quiet", submodulePath, "libs/sub")
+	runGit(suite.T(), path, "commit", "--quiet", "--message", "add submodule")
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{Submodules: true})
+
+	assert.Contains(suite.T(), gitFiles, GitFile{
+		Sha:      submoduleCommit,
+		HashAlgo: HashAlgoSHA1,
+		Type:     GitFileTypeSubmodule,
+		Filepath: "libs/sub",
+		URL:      submodulePath,
+		InHead:   true,
+	})
+	assert.Len(suite.T(), gitFiles, 3)
+}
+
+func (suite *ExtractorTestSuite) TestRunFirstSeen() {
+	path := createTestGitRepository(suite.T(),
+		map[string]string{"a.txt": "one\n"},
+		map[string]string{"a.txt": "two\n", "b.txt": "one\n"},
+	)
+
+	first := strings.Fields(runGit(suite.T(), path, "show", "--no-patch", "--format=%H %aI", "HEAD~1"))
+	second := strings.Fields(runGit(suite.T(), path, "show", "--no-patch", "--format=%H %aI", "HEAD"))
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{FirstSeen: true})
+
+	// The blob of b.txt is the first blob of a.txt: it is listed once, but attributed to the first commit
+	assert.ElementsMatch(suite.T(), []GitFile{
+		{
+			Sha: "5626abf0f72e58d7a153368ba57db4c673c0e171", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "b.txt", Size: 4,
+			FirstCommit: first[0], FirstCommitDate: first[1], FirstCommitAuthorEmail: "author@example.com",
+			InHead: true,
+		},
+		{
+			Sha: "f719efd430d52bcfc8566a43b2eb655688d38871", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "a.txt", Size: 4,
+			FirstCommit: second[0], FirstCommitDate: second[1], FirstCommitAuthorEmail: "author@example.com",
+			InHead: true,
+		},
+	}, gitFiles)
+}
+
+func (suite *ExtractorTestSuite) TestRunInHead() {
+	path := createTestGitRepository(suite.T(),
+		map[string]string{"a.txt": "one\n", "b.txt": "secret\n"},
+		map[string]string{"a.txt": "two\n"},
+	)
+	runGit(suite.T(), path, "rm", "--quiet", "b.txt")
+	runGit(suite.T(), path, "commit", "--quiet", "--message", "remove b.txt")
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{})
+
+	assert.ElementsMatch(suite.T(), []GitFile{
+		{Sha: "f719efd430d52bcfc8566a43b2eb655688d38871", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "a.txt", Size: 4, InHead: true},
+		{Sha: "5626abf0f72e58d7a153368ba57db4c673c0e171", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "a.txt", Size: 4},
+		{Sha: "d97c5eada5d8c52079031eef0107a4430a9617c5", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "b.txt", Size: 7},
+	}, gitFiles)
+
+	gitFiles = extractGitFiles(path, ExtractorOptions{HeadOnly: true})
+
+	assert.Equal(suite.T(), []GitFile{
+		{Sha: "f719efd430d52bcfc8566a43b2eb655688d38871", HashAlgo: HashAlgoSHA1, Type: "blob", Filepath: "a.txt", Size: 4, InHead: true},
+	}, gitFiles)
+}
+
+func (suite *ExtractorTestSuite) TestRunRefs() {
+	path := createTestGitRepository(suite.T(), map[string]string{"a.txt": "a\n"})
+	defaultBranch := strings.TrimSpace(runGit(suite.T(), path, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	for _, branch := range []string{"release/1", "feature"} {
+		runGit(suite.T(), path, "checkout", "--quiet", "-b", branch)
+
+		if err := os.WriteFile(filepath.Join(path, filepath.Base(branch)+".txt"), []byte(branch), 0600); err != nil {
+			suite.T().Fatal(err)
+		}
+
+		runGit(suite.T(), path, "add", "--all")
+		runGit(suite.T(), path, "commit", "--quiet", "--message", branch)
+	}
+
+	runGit(suite.T(), path, "tag", "v1", "release/1")
+	runGit(suite.T(), path, "checkout", "--quiet", defaultBranch)
+
+	for refs, expected := range map[string][]string{
+		RefsDefault:                     {"a.txt"},
+		RefsBranches:                    {"a.txt", "1.txt", "feature.txt"},
+		RefsTags:                        {"a.txt", "1.txt"},
+		"refs/heads/release/*":          {"a.txt", "1.txt"},
+		"refs/heads/feature":            {"a.txt", "1.txt", "feature.txt"},
+		"refs/heads/does-not-exist/*":   {},
+		RefsAll + ",refs/heads/feature": {"a.txt", "1.txt", "feature.txt"},
+	} {
+		filepaths := make([]string, 0)
+		for _, gitFile := range extractGitFiles(path, ExtractorOptions{Refs: strings.Split(refs, ",")}) {
+			filepaths = append(filepaths, gitFile.Filepath)
+		}
+
+		assert.ElementsMatch(suite.T(), expected, filepaths, refs)
+	}
+}
+
+func (suite *ExtractorTestSuite) TestRunPaths() {
+	path := createTestGitRepository(suite.T(), map[string]string{
+		"main.go":                     "package main\n",
+		"docs/index.md":               "# Docs\n",
+		"vendor/lib/lib.go":           "package lib\n",
+		"web/node_modules/x/index.js": "module.exports = {}\n",
+	})
+
+	for _, test := range []struct {
+		include, exclude []string
+		expected         []string
+	}{
+		{nil, nil, []string{"main.go", "docs/index.md", "vendor/lib/lib.go", "web/node_modules/x/index.js"}},
+		{nil, VendoredPaths, []string{"main.go", "docs/index.md"}},
+		{[]string{"*.go"}, nil, []string{"main.go", "vendor/lib/lib.go"}},
+		{[]string{"*.go"}, VendoredPaths, []string{"main.go"}},
+		{nil, []string{"*", "!*.md"}, []string{"docs/index.md"}},
+	} {
+		filepaths := make([]string, 0)
+		for _, gitFile := range extractGitFiles(path, ExtractorOptions{IncludePaths: test.include, ExcludePaths: test.exclude}) {
+			filepaths = append(filepaths, gitFile.Filepath)
+		}
+
+		assert.ElementsMatch(suite.T(), test.expected, filepaths, "include %v exclude %v", test.include, test.exclude)
+	}
+
+	// The same content at an included path and at an excluded path is selected and reported at the included path
+	path = createTestGitRepository(suite.T(), map[string]string{
+		"node_modules/pkg/a.js": "a\n",
+		"src/a.js":              "a\n",
+		"src/b.js":              "b\n",
+		"vendor/b.js":           "b\n",
+	})
+
+	for _, test := range []struct {
+		include, exclude []string
+		expected         []string
+	}{
+		{[]string{"src/"}, nil, []string{"src/a.js", "src/b.js"}},
+		{[]string{"vendor/"}, nil, []string{"vendor/b.js"}},
+		{[]string{"node_modules/"}, nil, []string{"node_modules/pkg/a.js"}},
+		{nil, VendoredPaths, []string{"src/a.js", "src/b.js"}},
+		{nil, []string{"src/"}, []string{"node_modules/pkg/a.js", "vendor/b.js"}},
+	} {
+		filepaths := make([]string, 0)
+		for _, gitFile := range extractGitFiles(path, ExtractorOptions{IncludePaths: test.include, ExcludePaths: test.exclude}) {
+			filepaths = append(filepaths, gitFile.Filepath)
+		}
+
+		assert.ElementsMatch(suite.T(), test.expected, filepaths, "include %v exclude %v", test.include, test.exclude)
+	}
+}
+
+func (suite *ExtractorTestSuite) TestRunSizeAndCommonFiles() {
+	path := createTestGitRepository(suite.T(), map[string]string{
+		"pkg/__init__.py": "",
+		".gitignore":      "node_modules\n",
+		"small.txt":       "a\n",
+		"big.txt":         strings.Repeat("big\n", 25),
+	})
+
+	for _, test := range []struct {
+		options  ExtractorOptions
+		expected []string
+	}{
+		{ExtractorOptions{}, []string{"pkg/__init__.py", ".gitignore", "small.txt", "big.txt"}},
+		{ExtractorOptions{SkipCommonFiles: true}, []string{"small.txt", "big.txt"}},
+		{ExtractorOptions{MinSize: 2}, []string{".gitignore", "small.txt", "big.txt"}},
+		{ExtractorOptions{MinSize: 2, MaxSize: 13}, []string{".gitignore", "small.txt"}},
+		{ExtractorOptions{MaxSize: 100, SkipCommonFiles: true}, []string{"small.txt", "big.txt"}},
+	} {
+		filepaths := make([]string, 0)
+		for _, gitFile := range extractGitFiles(path, test.options) {
+			filepaths = append(filepaths, gitFile.Filepath)
+		}
+
+		assert.ElementsMatch(suite.T(), test.expected, filepaths, "%+v", test.options)
+	}
+}
+
+func (suite *ExtractorTestSuite) TestRunContentHash() {
+	path := createTestGitRepository(suite.T(), map[string]string{
+		"README.md":   "hello\n",
+		"archive.zip": lfsPointerContent,
+	})
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{LFS: true, ContentHash: ContentHashSHA256})
+
+	assert.ElementsMatch(suite.T(), []GitFile{
+		{
+			Sha:      "ce013625030ba8dba906f756967f9e9ca394464a",
+			HashAlgo: HashAlgoSHA1,
+			Type:     "blob",
+			Filepath: "README.md",
+			Size:     6,
+			InHead:   true,
+			SHA256:   "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		},
+		{
+			Sha:      "60c8d8ab2adcf57a391163a7eeb0cdb8bf348e44",
+			HashAlgo: HashAlgoSHA1,
+			Type:     "blob",
+			Filepath: "archive.zip",
+			Size:     130,
+			InHead:   true,
+			SHA256:   "e8ea3d2b03f8134006887f399ab043650324c06409a93810f824bd6db256cead",
+		},
+		{
+			Sha:      "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+			HashAlgo: HashAlgoSHA256,
+			Type:     GitFileTypeLFS,
+			Filepath: "archive.zip",
+			Size:     12345,
+			InHead:   true,
+			SHA256:   "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+		},
+	}, gitFiles)
+}
+
+func (suite *ExtractorTestSuite) TestRunSHA256ObjectFormat() {
+	path := createTestGitRepositoryWithFormat(suite.T(), HashAlgoSHA256, map[string]string{
+		"README.md":   "hello\n",
+		"archive.zip": lfsPointerContent,
+	})
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{LFS: true, FirstSeen: true, ContentHash: ContentHashSHA256})
+
+	commit := strings.Fields(runGit(suite.T(), path, "show", "--no-patch", "--format=%H %aI", "HEAD"))
+	assert.Len(suite.T(), commit[0], 64)
+
+	assert.Len(suite.T(), gitFiles, 3)
+	assert.Contains(suite.T(), gitFiles, GitFile{
+		Sha:                    "2cf8d83d9ee29543b34a87727421fdecb7e3f3a183d337639025de576db9ebb4",
+		HashAlgo:               HashAlgoSHA256,
+		Type:                   "blob",
+		Filepath:               "README.md",
+		Size:                   6,
+		FirstCommit:            commit[0],
+		FirstCommitDate:        commit[1],
+		FirstCommitAuthorEmail: "author@example.com",
+		InHead:                 true,
+		SHA256:                 "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	})
+	assert.Contains(suite.T(), gitFiles, GitFile{
+		Sha:      "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+		HashAlgo: HashAlgoSHA256,
+		Type:     GitFileTypeLFS,
+		Filepath: "archive.zip",
+		Size:     12345,
+		InHead:   true,
+		SHA256:   "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+	})
+}
+
+func (suite *ExtractorTestSuite) TestRunWinnowing() {
+	path := createTestGitRepository(suite.T(), map[string]string{
+		"main.go":   winnowingGoSource,
+		"image.png": "PNG\x00" + winnowingGoSource,
+		"README.md": "hello\n",
+	})
+
+	gitFiles := extractGitFiles(path, ExtractorOptions{Winnowing: true})
+
+	winnowingFiles := make([]GitFile, 0)
+
+	for _, gitFile := range gitFiles {
+		if gitFile.Type == GitFileTypeWinnowing {
+			winnowingFiles = append(winnowingFiles, gitFile)
+		}
+	}
+
+	assert.Len(suite.T(), gitFiles, 4)
+
+	if assert.Len(suite.T(), winnowingFiles, 1) {
+		assert.Equal(suite.T(), "main.go", winnowingFiles[0].Filepath)
+		assert.Equal(suite.T(), int64(len(winnowingGoSource)), winnowingFiles[0].Size)
+		assert.Equal(suite.T(), winnow([]byte(winnowingGoSource), commentStyleOf("Go")), winnowingFiles[0].Fingerprints)
+	}
+}
+
+func (suite *ExtractorTestSuite) TestRunClassify() {
+	path := createTestGitRepository(suite.T(), map[string]string{
+		"main.go":     winnowingGoSource,
+		"bin/deploy":  "#!/usr/bin/env bash\necho deploy\n",
+		"logo.png":    "PNG\x00\x01",
+		"archive.zip": lfsPointerContent,
+	})
+
+	binary := make(map[string]*bool)
+	languages := make(map[string]string)
+
+	for _, gitFile := range extractGitFiles(path, ExtractorOptions{LFS: true, Classify: true}) {
+		binary[gitFile.Type+":"+gitFile.Filepath] = gitFile.IsBinary
+		languages[gitFile.Type+":"+gitFile.Filepath] = gitFile.Language
+	}
+
+	falseValue, trueValue := false, true
+
+	assert.Equal(suite.T(), map[string]*bool{
+		"blob:main.go":     &falseValue,
+		"blob:bin/deploy":  &falseValue,
+		"blob:logo.png":    &trueValue,
+		"blob:archive.zip": &falseValue,
+		"lfs:archive.zip":  nil,
+	}, binary)
+	assert.Equal(suite.T(), map[string]string{
+		"blob:main.go":     "Go",
+		"blob:bin/deploy":  "Shell",
+		"blob:logo.png":    "",
+		"blob:archive.zip": "",
+		"lfs:archive.zip":  "",
+	}, languages)
+}
+
+func (suite *ExtractorTestSuite) TestRunCanceled() {
+	files := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		files[fmt.Sprintf("file%d.txt", i)] = fmt.Sprintf("content %d\n", i)
+	}
+
+	path := createTestGitRepository(suite.T(), files)
+
+	for _, options := range []ExtractorOptions{{}, {LFS: true, ContentHash: ContentHashSHA256}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		gitFiles := NewFastExtractor(options).Run(ctx, path, "")
+
+		<-gitFiles
+		cancel()
+
+		// Leave the extraction time to stop while the channel is not read
+		time.Sleep(100 * time.Millisecond)
+
+		remaining := make(chan int)
+
+		go func() {
+			count := 0
+			for range gitFiles {
+				count++
+			}
+
+			remaining <- count
+		}()
+
+		select {
+		case count := <-remaining:
+			assert.Less(suite.T(), count, 10, "%+v", options)
+		case <-time.After(10 * time.Second):
+			suite.T().Fatalf("extraction not stopped on cancellation: %+v", options)
+		}
+	}
+}
+
+func (suite *ExtractorTestSuite) TestValidateContentHash() {
+	assert.NoError(suite.T(), ValidateContentHash(""))
+	assert.NoError(suite.T(), ValidateContentHash(ContentHashSHA256))
+	assert.Error(suite.T(), ValidateContentHash("md5"))
+}
+
+func (suite *ExtractorTestSuite) TestValidateRefs() {
+	assert.NoError(suite.T(), ValidateRefs([]string{RefsDefault, RefsTags, "refs/heads/release/*"}))
+	assert.Error(suite.T(), ValidateRefs([]string{"main"}))
+}
+
+func (suite *ExtractorTestSuite) TestParseLFSPointer() {
+	pointer, ok := parseLFSPointer([]byte(lfsPointerContent))
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), lfsPointer{
+		OID:  "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393",
+		Size: 12345,
+	}, pointer)
+
+	_, ok = parseLFSPointer([]byte("version 1\noid sha256:abc\nsize 1\n"))
+	assert.False(suite.T(), ok)
+
+	_, ok = parseLFSPointer([]byte("hello\n"))
+	assert.False(suite.T(), ok)
+}
+
+func TestExtractor(t *testing.T) {
+	suite.Run(t, new(ExtractorTestSuite))
+}
@@ -0,0 +1,86 @@
+package srcfingerprint
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// firstSeenCommitPrefix starts the commit lines of the history walk, which can not appear in the raw diff lines.
+const firstSeenCommitPrefix = "\x01"
+
+// firstSeen is the first commit introducing a blob.
+type firstSeen struct {
+	Commit      string
+	Date        string
+	AuthorEmail string
+}
+
+// firstSeenCommits walks the history of revisions in the repository at path once, from the oldest commits,
+// and returns the first commit introducing each blob.
+//
+// Merges are diffed against each of their parents, so a blob introduced by the resolution of a conflict
+// is attributed to the merge. A blob reachable from several branches is attributed to the first commit
+// in topological order.
+func firstSeenCommits(ctx context.Context, path string, revisions []string) (map[string]firstSeen, error) {
+	args := []string{"log", "--reverse", "--topo-order", "--root", "-m",
+		"--raw", "--no-abbrev", "--no-renames", "--format=" + firstSeenCommitPrefix + "%H %aI %ae"}
+
+	cmd := exec.CommandContext(ctx, "git", append(append(args, revisions...), "--")...)
+	cmd.Dir = path
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	commits := make(map[string]firstSeen)
+	scanner := bufio.NewScanner(stdout)
+	// Paths can be long, the lines are not limited to the default 64KB
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var current firstSeen
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, firstSeenCommitPrefix):
+			fields := strings.SplitN(strings.TrimPrefix(line, firstSeenCommitPrefix), " ", 3)
+			if len(fields) != 3 {
+				continue
+			}
+
+			current = firstSeen{Commit: fields[0], Date: fields[1], AuthorEmail: fields[2]}
+		case strings.HasPrefix(line, ":"):
+			// :<old mode> <new mode> <old sha> <new sha> <status>\t<path>
+			fields := strings.Fields(strings.SplitN(line, "\t", 2)[0])
+			if len(fields) != 5 || strings.HasPrefix(fields[4], "D") {
+				continue
+			}
+
+			if _, found := commits[fields[3]]; !found {
+				commits[fields[3]] = current
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		return nil, err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("unable to walk the history: %w", err)
+	}
+
+	return commits, nil
+}
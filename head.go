@@ -0,0 +1,31 @@
+package srcfingerprint
+
+import (
+	"bytes"
+	"os/exec"
+)
+
+// headObjects returns the set of the blobs and submodule commits of the tree of HEAD,
+// the default branch of a clone.
+func headObjects(path string) (map[string]bool, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", "-z", "HEAD")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]bool)
+
+	// Each entry is "<mode> <type> <sha>\t<path>"
+	for _, entry := range bytes.Split(output, []byte{0}) {
+		info := bytes.SplitN(entry, []byte{'\t'}, 2)[0]
+
+		if fields := bytes.Fields(info); len(fields) == 3 {
+			objects[string(fields[2])] = true
+		}
+	}
+
+	return objects, nil
+}
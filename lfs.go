@@ -0,0 +1,94 @@
+package srcfingerprint
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+const (
+	// lfsPointerMaxSize is the maximum size of a Git LFS pointer file.
+	lfsPointerMaxSize = 1024
+	lfsOIDPrefix      = "sha256:"
+	lfsOIDLength      = 64
+)
+
+// lfsSpecVersions are the versions a Git LFS pointer file can start with.
+var lfsSpecVersions = []string{
+	"https://git-lfs.github.com/spec/v1",
+	"https://hawser.github.com/spec/v1",
+}
+
+// lfsPointer is the content of a Git LFS pointer file.
+type lfsPointer struct {
+	OID  string
+	Size int64
+}
+
+// parseLFSPointer parses a Git LFS pointer file, it returns false if content is not a pointer.
+// See https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md
+func parseLFSPointer(content []byte) (lfsPointer, bool) {
+	var (
+		pointer lfsPointer
+		hasSize bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for line := 0; scanner.Scan(); line++ {
+		key, value, found := cutString(scanner.Text(), " ")
+		if !found {
+			return lfsPointer{}, false
+		}
+
+		if line == 0 {
+			if key != "version" || !containsString(lfsSpecVersions, value) {
+				return lfsPointer{}, false
+			}
+
+			continue
+		}
+
+		switch key {
+		case "oid":
+			if !strings.HasPrefix(value, lfsOIDPrefix) || len(value) != len(lfsOIDPrefix)+lfsOIDLength {
+				return lfsPointer{}, false
+			}
+
+			pointer.OID = strings.TrimPrefix(value, lfsOIDPrefix)
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return lfsPointer{}, false
+			}
+
+			pointer.Size, hasSize = size, true
+		}
+	}
+
+	if pointer.OID == "" || !hasSize {
+		return lfsPointer{}, false
+	}
+
+	return pointer, true
+}
+
+// cutString slices s around the first instance of sep.
+func cutString(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package srcfingerprint
+
+import (
+	"os/exec"
+	"strings"
+)
+
+const (
+	// HashAlgoSHA1 is the hash algorithm of the object ids of most repositories.
+	HashAlgoSHA1 = "sha1"
+	// HashAlgoSHA256 is the hash algorithm of the object ids of repositories initialized with --object-format=sha256,
+	// and of the object ids of Git LFS objects.
+	HashAlgoSHA256 = "sha256"
+)
+
+// objectFormat returns the hash algorithm of the object ids of the repository at path.
+func objectFormat(path string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-object-format")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	// Versions of git older than 2.25 print the unknown option back, they only support SHA-1
+	if format := strings.TrimSpace(string(output)); format == HashAlgoSHA256 {
+		return HashAlgoSHA256, nil
+	}
+
+	return HashAlgoSHA1, nil
+}
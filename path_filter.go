@@ -0,0 +1,89 @@
+package srcfingerprint
+
+import (
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// VendoredPaths are gitignore patterns matching the vendored dependencies of the common ecosystems.
+var VendoredPaths = []string{
+	// Go, PHP, Ruby
+	"vendor/",
+	// JavaScript
+	"node_modules/",
+	"bower_components/",
+	"jspm_packages/",
+	"web_modules/",
+	".yarn/cache/",
+	".pnpm-store/",
+	// Python
+	"site-packages/",
+	"__pypackages__/",
+	".venv/",
+	// Ruby
+	".bundle/",
+	// iOS
+	"Pods/",
+	"Carthage/Checkouts/",
+	// C, C++ and others
+	"third_party/",
+	"third-party/",
+	"thirdparty/",
+}
+
+// pathFilter selects files given gitignore patterns.
+type pathFilter struct {
+	include gitignore.Matcher
+	exclude gitignore.Matcher
+}
+
+func newPathFilter(include, exclude []string) *pathFilter {
+	filter := &pathFilter{}
+
+	if len(include) > 0 {
+		filter.include = newPathMatcher(include)
+	}
+
+	if len(exclude) > 0 {
+		filter.exclude = newPathMatcher(exclude)
+	}
+
+	return filter
+}
+
+func newPathMatcher(patterns []string) gitignore.Matcher {
+	parsed := make([]gitignore.Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		parsed = append(parsed, gitignore.ParsePattern(pattern, nil))
+	}
+
+	return gitignore.NewMatcher(parsed)
+}
+
+// IsSet returns true if any pattern is set.
+func (f *pathFilter) IsSet() bool {
+	return f.include != nil || f.exclude != nil
+}
+
+// Select returns the first of paths matching the filter.
+func (f *pathFilter) Select(paths []string) (string, bool) {
+	for _, path := range paths {
+		if f.Match(path) {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// Match returns true if path matches an include pattern, if any, and no exclude pattern.
+func (f *pathFilter) Match(path string) bool {
+	parts := strings.Split(path, "/")
+
+	if f.include != nil && !f.include.Match(parts, false) {
+		return false
+	}
+
+	return f.exclude == nil || !f.exclude.Match(parts, false)
+}
@@ -32,6 +32,8 @@ type ResultCommitPipelineEvent struct {
 type ResultGitFilePipelineEvent struct {
 	Repository provider.GitRepository
 	GitFile    *GitFile
+	// Source is the name of the source of the repository
+	Source string
 }
 
 // RepositoryPipelineEvent represents an event from a repository.
@@ -52,11 +54,23 @@ type CommitPipelineEvent struct {
 	Repository string
 }
 
+// errExtractionTimeout is the error returned when the extraction of a repository is interrupted by the timeout.
+var errExtractionTimeout = errors.New("timeout reached while extracting files")
+
 // Pipeline represents the whole extraction pipeline.
 type Pipeline struct {
 	Provider provider.Provider
 	Cloner   cloner.Cloner
 	Analyzer *Analyzer
+	// DiskBudget limits the disk space used by simultaneous clones. No limit is applied if nil.
+	DiskBudget *DiskBudget
+	// ExtractorOptions are the options of the extractor run on each repository.
+	ExtractorOptions ExtractorOptions
+	// Source is the name of the source of the repositories, it is reported in the results.
+	Source string
+	// RecurseSubmodules extracts the submodules hosted on the same host as their parent repository.
+	// It requires ExtractorOptions.Submodules.
+	RecurseSubmodules bool
 
 	ClonersCount int
 }
@@ -106,21 +120,74 @@ func (p *Pipeline) gather(
 	log.Infoln("Done gathering repositories")
 }
 
+// cloneRepository clones a repository once it fits in the disk budget.
+// When the clone fails because the device is full, it waits for other clones to be released and retries.
+// On success, the disk budget reservation must be released by the caller.
+func (p *Pipeline) cloneRepository(ctx context.Context, repository provider.GitRepository) (string, error) {
+	for {
+		if p.DiskBudget != nil {
+			if err := p.DiskBudget.Acquire(ctx, repository.GetStorageSize()); err != nil {
+				return "", err
+			}
+		}
+
+		log.Infof("Cloning repo %v\n", repository.GetName())
+
+		gitRepository, err := p.Provider.CloneRepository(ctx, p.Cloner, repository)
+		if err == nil || p.DiskBudget == nil {
+			return gitRepository, err
+		}
+
+		if !errors.Is(err, cloner.ErrNoSpaceLeft) {
+			p.DiskBudget.Release(repository.GetStorageSize())
+
+			return "", err
+		}
+
+		log.Warnf("No space left on device while cloning %v, waiting for other clones to finish\n", repository.GetName())
+
+		if !p.DiskBudget.WaitForRelease(ctx, repository.GetStorageSize()) {
+			return "", err
+		}
+	}
+}
+
 // ExtractRepository extracts for a single repository.
 func (p *Pipeline) ExtractRepository(ctx context.Context, repository provider.GitRepository, after string, eventChan chan<- PipelineEvent) error { // nolint
-	defer p.publishEvent(eventChan, RepositoryPipelineEvent{true, repository.GetPrivate(), repository.GetName()})
+	_, err := p.extractRepository(ctx, repository, after, eventChan)
+
+	return err
+}
 
-	log.Infof("Cloning repo %v\n", repository.GetName())
+// extractRepository extracts for a single repository and returns the submodules found.
+func (p *Pipeline) extractRepository(
+	ctx context.Context,
+	repository provider.GitRepository,
+	after string,
+	eventChan chan<- PipelineEvent) ([]*GitFile, error) {
+	defer p.publishEvent(eventChan, RepositoryPipelineEvent{true, repository.GetPrivate(), repository.GetName()})
 
-	gitRepository, err := p.Provider.CloneRepository(ctx, p.Cloner, repository)
+	gitRepository, err := p.cloneRepository(ctx, repository)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if p.DiskBudget != nil {
+		defer p.DiskBudget.Release(repository.GetStorageSize())
 	}
 
+	defer func() {
+		if err := p.Cloner.Release(gitRepository); err != nil {
+			log.Errorf("Unable to remove cloned directory %s: %v\n", gitRepository, err)
+		}
+	}()
+
 	log.Infof("Cloned repo %v (size: %v KB)\n", repository.GetName(), repository.GetStorageSize())
 
-	extractorGitFile := NewFastExtractor()
-	extractorGitFile.Run(gitRepository, after)
+	extractorGitFile := NewFastExtractor(p.ExtractorOptions)
+	extractorGitFile.Run(ctx, gitRepository, after)
+
+	submodules := make([]*GitFile, 0)
 
 loop:
 	for {
@@ -129,15 +196,60 @@ loop:
 			if !opened {
 				break loop
 			}
-			p.publishEvent(eventChan, ResultGitFilePipelineEvent{repository, gitFile})
+			p.publishEvent(eventChan, ResultGitFilePipelineEvent{repository, gitFile, p.Source})
+
+			if gitFile.Type == GitFileTypeSubmodule {
+				submodules = append(submodules, gitFile)
+			}
 		case <-ctx.Done():
-			return errors.New("timeout reached while extracting files")
+			// The extractor stops on cancellation, it is drained so that its git processes are stopped
+			// before the clone is released
+			for range extractorGitFile.ChanGitFiles {
+			}
+
+			return nil, errExtractionTimeout
 		}
 	}
 
+	// The extractor may have stopped on cancellation before the timeout is noticed
+	if ctx.Err() != nil {
+		return nil, errExtractionTimeout
+	}
+
 	log.Infof("Done extracting %v\n", repository.GetName())
 
-	return nil
+	return submodules, nil
+}
+
+// extractRepositoryAndSubmodules extracts a repository, then its submodules if RecurseSubmodules is set.
+// Repositories already in extracted are skipped, so each of them is extracted once.
+func (p *Pipeline) extractRepositoryAndSubmodules(
+	ctx context.Context,
+	repository provider.GitRepository,
+	after string,
+	eventChan chan<- PipelineEvent,
+	extracted *repositorySet) {
+	submodules, err := p.extractRepository(ctx, repository, after, eventChan)
+	if err != nil {
+		log.Errorf("extracting %v failed: %v\n", repository.GetName(), err)
+
+		return
+	}
+
+	if !p.RecurseSubmodules {
+		return
+	}
+
+	for _, submodule := range submodules {
+		submoduleRepository, ok := submoduleRepository(repository, submodule.URL)
+		if !ok || !extracted.Add(submoduleRepository) {
+			continue
+		}
+
+		log.Infof("Extracting submodule %v of %v\n", submodule.Filepath, repository.GetName())
+		p.publishEvent(eventChan, RepositoryListPipelineEvent{[]provider.GitRepository{submoduleRepository}})
+		p.extractRepositoryAndSubmodules(ctx, submoduleRepository, after, eventChan, extracted)
+	}
 }
 
 const (
@@ -177,6 +289,8 @@ func (p *Pipeline) ExtractRepositories(
 
 	go p.gather(&wg, eventChan, object, repositoryChannel, limit)
 
+	extracted := newRepositorySet()
+
 	for i := 0; i < extractionWorkersCount; i++ {
 		wg.Add(1)
 
@@ -184,9 +298,13 @@ func (p *Pipeline) ExtractRepositories(
 			defer wg.Done()
 
 			for repository := range repositoryChannel {
-				if err := p.ExtractRepository(ctx, repository, after, eventChan); err != nil {
-					log.Errorf("extracting %v failed: %v\n", repository.GetName(), err)
+				if !extracted.Add(repository) {
+					log.Infof("Skipping %v, already extracted\n", repository.GetName())
+
+					continue
 				}
+
+				p.extractRepositoryAndSubmodules(ctx, repository, after, eventChan, extracted)
 			}
 		}(ctx)
 	}
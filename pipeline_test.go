@@ -2,9 +2,12 @@ package srcfingerprint
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
 	"srcfingerprint/cloner"
 	"srcfingerprint/provider"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -43,6 +46,7 @@ func (m gitRepositoryMock) GetHTTPUrl() string      { return "" }
 func (m gitRepositoryMock) GetCreatedAt() time.Time { return time.Unix(0, 0) }
 func (m gitRepositoryMock) GetStorageSize() int64   { return 0 }
 func (m gitRepositoryMock) GetPrivate() bool        { return true }
+func (m gitRepositoryMock) GetVisibility() string   { return "private" }
 
 func createGitRepository(name string) provider.GitRepository {
 	return gitRepositoryMock{name: name}
@@ -186,6 +190,127 @@ func (suite *PipelineTestSuite) TestExtractRepositories() {
 	assert.Equal(suite.T(), expectedEvents, events)
 }
 
+func (suite *PipelineTestSuite) TestExtractRepositoriesSHA256ObjectFormat() {
+	path := createTestGitRepositoryWithFormat(suite.T(), HashAlgoSHA256, map[string]string{"README.md": "hello\n"})
+
+	pipeline := Pipeline{
+		Provider:         provider.NewGenericProvider(provider.Options{RepositoryName: "sha256"}),
+		Cloner:           cloner.NewDiskCloner(suite.T().TempDir()),
+		ExtractorOptions: ExtractorOptions{FirstSeen: true},
+	}
+
+	eventChan := make(chan PipelineEvent)
+
+	go func() {
+		defer close(eventChan)
+
+		pipeline.ExtractRepositories(path, "", eventChan, 0, 0)
+	}()
+
+	gitFiles := make([]*GitFile, 0)
+
+	for event := range eventChan {
+		if result, ok := event.(ResultGitFilePipelineEvent); ok {
+			gitFiles = append(gitFiles, result.GitFile)
+		}
+	}
+
+	if !assert.Len(suite.T(), gitFiles, 1) {
+		return
+	}
+
+	assert.Equal(suite.T(), "2cf8d83d9ee29543b34a87727421fdecb7e3f3a183d337639025de576db9ebb4", gitFiles[0].Sha)
+	assert.Equal(suite.T(), HashAlgoSHA256, gitFiles[0].HashAlgo)
+	assert.Len(suite.T(), gitFiles[0].FirstCommit, 64)
+}
+
+// processesIn returns the ids of the processes whose working directory is in dir.
+func processesIn(dir string) []string {
+	cwds, _ := filepath.Glob("/proc/[0-9]*/cwd")
+	processes := make([]string, 0)
+
+	for _, cwd := range cwds {
+		if target, err := os.Readlink(cwd); err == nil && strings.HasPrefix(target, dir) {
+			processes = append(processes, filepath.Base(filepath.Dir(cwd)))
+		}
+	}
+
+	return processes
+}
+
+// processCheckingCloner records the processes running in a clone when it is released.
+type processCheckingCloner struct {
+	*cloner.DiskCloner
+	processes []string
+}
+
+func (c *processCheckingCloner) Release(path string) error {
+	c.processes = append(c.processes, processesIn(path)...)
+
+	return c.DiskCloner.Release(path)
+}
+
+func (suite *PipelineTestSuite) TestExtractRepositoryTimeout() {
+	if _, err := os.Stat("/proc/self/cwd"); err != nil {
+		suite.T().Skip("the working directories of the processes are not available")
+	}
+
+	files := make(map[string]string)
+	for i := 0; i < 200; i++ {
+		files[fmt.Sprintf("file%d.txt", i)] = fmt.Sprintf("content %d\n", i)
+	}
+
+	path := createTestGitRepository(suite.T(), files)
+	cloneDir := suite.T().TempDir()
+	diskCloner := &processCheckingCloner{DiskCloner: cloner.NewDiskCloner(cloneDir)}
+
+	pipeline := Pipeline{
+		Provider:         provider.NewGenericProvider(provider.Options{RepositoryName: "repository"}),
+		Cloner:           diskCloner,
+		ExtractorOptions: ExtractorOptions{LFS: true, ContentHash: ContentHashSHA256},
+	}
+
+	repositories, err := pipeline.Provider.Gather(path)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventChan := make(chan PipelineEvent)
+	extracted := make(chan error)
+
+	go func() {
+		defer close(eventChan)
+
+		extracted <- pipeline.ExtractRepository(ctx, repositories[0], "", eventChan)
+	}()
+
+	// The extraction times out once the first file is extracted
+	for event := range eventChan {
+		if _, ok := event.(ResultGitFilePipelineEvent); ok {
+			cancel()
+
+			break
+		}
+	}
+
+	go func() {
+		for range eventChan {
+		}
+	}()
+
+	assert.Error(suite.T(), <-extracted)
+
+	// The git processes are stopped before the clone is released
+	assert.Empty(suite.T(), diskCloner.processes)
+	assert.Empty(suite.T(), processesIn(cloneDir))
+
+	entries, _ := os.ReadDir(cloneDir)
+	assert.Empty(suite.T(), entries)
+}
+
 func TestPipeline(t *testing.T) {
 	suite.Run(t, new(PipelineTestSuite))
 }
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -16,10 +17,9 @@ import (
 
 // BitbucketProvider is capable of gathering Bitbucket server repositories from an org.
 type BitbucketProvider struct {
-	client    *bitbucket.Client
-	transport *AuthHeaderTransport
-	options   Options
-	token     string
+	client  *bitbucket.Client
+	options Options
+	token   string
 }
 
 const LastPage = -1
@@ -46,31 +46,35 @@ func createFromBitbucketRepo(r *bitbucket.Repository) *Repository {
 		httpURL:     httpURL,
 		createdAt:   time.Now(),
 		storageSize: 0,
+		private:     !r.Public,
 	}
 }
 
+// bitbucketURLExample is an example of the API URL of a Bitbucket server.
+const bitbucketURLExample = "http://examplebb.com/rest/api/1.0/"
+
+var (
+	// ErrInvalidBitbucketToken is the error returned when Bitbucket does not authenticate the token.
+	ErrInvalidBitbucketToken = errors.New("the Bitbucket token is invalid or expired")
+	// ErrMissingBitbucketURL is the error returned when the API URL of the Bitbucket server is not set.
+	ErrMissingBitbucketURL = errors.New("the Bitbucket provider requires an API URL such as " + bitbucketURLExample)
+)
+
 type AuthHeaderTransport struct {
-	T      http.RoundTripper
-	token  string
-	user   string
-	userID string
+	T     http.RoundTripper
+	token string
 }
 
 func (at *AuthHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Add("Authorization", "Bearer "+at.token)
-	resp, err := at.T.RoundTrip(req)
-
-	if resp != nil && resp.Header.Get("x-auserid") != "" {
-		at.userID = resp.Header.Get("x-auserid")
-		at.user = resp.Header.Get("x-ausername")
-	}
 
-	return resp, err
+	return at.T.RoundTrip(req)
 }
 
 func NewAuthHeaderTransport(T http.RoundTripper, token string) *AuthHeaderTransport {
 	if T == nil {
 		T = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
 			Dial: (&net.Dialer{
 				Timeout: BitbucketTimeout,
 			}).Dial,
@@ -79,26 +83,25 @@ func NewAuthHeaderTransport(T http.RoundTripper, token string) *AuthHeaderTransp
 	}
 
 	return &AuthHeaderTransport{
-		T:      T,
-		token:  token,
-		user:   "",
-		userID: "",
+		T:     T,
+		token: token,
 	}
 }
 
 // NewBitbucketProvider creates a new Bitbucket provider.
-func NewBitbucketProvider(token string, options Options) Provider {
+// If token is not empty, it is checked by resolving the authenticated user.
+func NewBitbucketProvider(token string, options Options) (Provider, error) {
 	// BaseURL should be like http://localhost:7990/rest/api/1.0/
 	if options.BaseURL == "" {
-		panic("This provider requires an API URL of form: http://examplebb.com/rest/api/1.0/")
+		return nil, ErrMissingBitbucketURL
 	}
 
-	_, err := url.Parse(options.BaseURL)
-	if err != nil {
-		panic(fmt.Sprintf("Bitbucket Base URL is not a valid url: %v. Should be of form: http://examplebb.com/rest/api/1.0/", options.BaseURL)) // nolint
+	if _, err := url.Parse(options.BaseURL); err != nil {
+		return nil, fmt.Errorf("the Bitbucket API URL %s is not valid, expected a URL such as %s: %w",
+			options.BaseURL, bitbucketURLExample, err)
 	}
 
-	transport := NewAuthHeaderTransport(nil, token)
+	transport := NewAuthHeaderTransport(options.Transport, token)
 	netClient := &http.Client{
 		Timeout:   BitbucketClientTimeout,
 		Transport: transport,
@@ -106,21 +109,70 @@ func NewBitbucketProvider(token string, options Options) Provider {
 
 	client, err := bitbucket.NewServerClient(options.BaseURL, netClient)
 	if err != nil {
-		panic(fmt.Sprintf("Unable to create bitbucket client: %v.", err))
+		return nil, fmt.Errorf("unable to create the Bitbucket client: %w", err)
+	}
+
+	provider := &BitbucketProvider{client: client, options: options, token: token}
+
+	if token != "" {
+		user, err := provider.authenticatedUser()
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("Authenticated on Bitbucket as %s\n", user)
 	}
 
-	return &BitbucketProvider{client: client, options: options, token: token, transport: transport}
+	return provider, nil
 }
 
-func (p *BitbucketProvider) gatherRepos(start int, project string) ([]GitRepository, int, error) {
-	opt := &bitbucket.ListRepositoriesOptions{ListOptions: bitbucket.ListOptions{Start: start, Limit: reposPerPage}}
-	if project != "" {
-		opt.ProjectName = project
+// authenticatedUser returns the slug of the user authenticated by the token.
+func (p *BitbucketProvider) authenticatedUser() (string, error) {
+	user, resp, err := p.client.Users.WhoAmI(context.Background())
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return "", ErrInvalidBitbucketToken
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the Bitbucket authenticated user: %w", err)
+	}
+
+	// Anonymous requests are answered with an empty user
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return "", ErrInvalidBitbucketToken
 	}
 
+	return user, nil
+}
+
+// bitbucketListPage lists a page of repositories starting at start.
+type bitbucketListPage func(start int) ([]*bitbucket.Repository, *bitbucket.Response, error)
+
+// listPage returns the function listing the repositories of object:
+// a personal project such as ~user, the repositories of a project given its name, or every repository if empty.
+func (p *BitbucketProvider) listPage(object string) bitbucketListPage {
+	if strings.HasPrefix(object, "~") {
+		return func(start int) ([]*bitbucket.Repository, *bitbucket.Response, error) {
+			return p.client.Repositories.ListByProject(context.Background(), object,
+				&bitbucket.ListOptions{Start: start, Limit: reposPerPage})
+		}
+	}
+
+	return func(start int) ([]*bitbucket.Repository, *bitbucket.Response, error) {
+		opt := &bitbucket.ListRepositoriesOptions{ListOptions: bitbucket.ListOptions{Start: start, Limit: reposPerPage}}
+		if object != "" {
+			opt.ProjectName = object
+		}
+
+		return p.client.Repositories.List(context.Background(), opt)
+	}
+}
+
+func (p *BitbucketProvider) gatherRepos(start int, listPage bitbucketListPage) ([]GitRepository, int, error) {
 	log.Infof("Gathering repos %v -> %v\n", start, start+reposPerPage)
 
-	repos, resp, err := p.client.Repositories.List(context.Background(), opt)
+	repos, resp, err := listPage(start)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -128,7 +180,8 @@ func (p *BitbucketProvider) gatherRepos(start int, project string) ([]GitReposit
 	repositories := make([]GitRepository, 0, len(repos))
 
 	for _, repo := range repos {
-		if repo.Origin != nil {
+		// Origin is only set for forks
+		if repo.Origin != nil && !p.options.IncludeForkedRepos {
 			continue
 		}
 
@@ -143,48 +196,60 @@ func (p *BitbucketProvider) gatherRepos(start int, project string) ([]GitReposit
 }
 
 func (p *BitbucketProvider) collect(
-	repositories []GitRepository, project string) []GitRepository {
+	repositories []GitRepository, listPage bitbucketListPage) []GitRepository {
 	var start = 0
 	for start != LastPage {
-		var err error
-
-		var pageRepositories []GitRepository
-
-		pageRepositories, start, err = p.gatherRepos(start, project)
+		pageRepositories, next, err := p.gatherRepos(start, listPage)
 		if err != nil {
+			// The next page is unknown, the repositories gathered so far are kept
 			log.Errorf("Error gathering start %v:%v\n", start, err)
 
-			continue
+			break
 		}
 
 		repositories = append(repositories, pageRepositories...)
+		start = next
 	}
 
 	return repositories
 }
 
-// Gather gather user's git repositories and send them to outputChannel.
+// gatherRepository returns the repository given by an object of form PROJECT/repo-slug.
+func (p *BitbucketProvider) gatherRepository(projectKey, slug string) ([]GitRepository, error) {
+	log.Infof("Gathering repository %s/%s\n", projectKey, slug)
+
+	repo, _, err := p.client.Repositories.Get(context.Background(), projectKey, slug)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get repository %s/%s: %w", projectKey, slug, err)
+	}
+
+	return []GitRepository{createFromBitbucketRepo(repo)}, nil
+}
+
+// Gather gathers the git repositories of an object: a project name, a personal project such as ~user,
+// a single repository such as PROJECT/repo-slug, or every repository the user can access if empty.
 func (p *BitbucketProvider) Gather(user string) ([]GitRepository, error) {
 	log.Infof("Gathering repositories for Bitbucket %s\n", user)
 
+	if parts := strings.SplitN(user, "/", 2); len(parts) == 2 {
+		return p.gatherRepository(parts[0], parts[1])
+	}
+
 	repositories := make([]GitRepository, 0)
 
-	repositories = p.collect(repositories, user)
+	repositories = p.collect(repositories, p.listPage(user))
 
 	return repositories, nil
 }
 
-// CloneRepository clones a Github repository given the token. The token must have the `read_repository` rights.
-func (p *BitbucketProvider) CloneRepository(ctx context.Context, cloner cloner.Cloner,
+// CloneRepository clones a Bitbucket repository given the token. The token must have the repository read permission.
+func (p *BitbucketProvider) CloneRepository(ctx context.Context, srcCloner cloner.Cloner,
 	repository GitRepository) (string, error) {
-	authURL := repository.GetSSHUrl()
 	// If token doesn't exist or if SSH cloning was specified, don't try to basic auth
-	if p.token != "" && !p.options.SSHCloning {
-		authURL = repository.GetHTTPUrl()
-		authURL = strings.Replace(authURL,
-			"https://", fmt.Sprintf("https://%s:%s@",
-				p.transport.user, url.QueryEscape(p.token)), 1)
+	if p.token == "" || p.options.SSHCloning {
+		return srcCloner.CloneRepository(ctx, repository.GetSSHUrl(), nil)
 	}
 
-	return cloner.CloneRepository(ctx, authURL)
+	// Bitbucket accepts personal access tokens as bearer tokens, no user name is required
+	return srcCloner.CloneRepository(ctx, repository.GetHTTPUrl(), cloner.BearerAuth(p.token))
 }
@@ -20,6 +20,18 @@ type Repository struct {
 	createdAt   time.Time
 	storageSize int64
 	private     bool
+	// visibility is derived from private if empty
+	visibility string
+}
+
+// NewRepository creates a Repository given its name and clone URLs.
+func NewRepository(name, sshURL, httpURL string, private bool) *Repository {
+	return &Repository{
+		name:    name,
+		sshURL:  sshURL,
+		httpURL: httpURL,
+		private: private,
+	}
 }
 
 // GetName returns the name of the repository.
@@ -40,6 +52,18 @@ func (r *Repository) GetStorageSize() int64 { return r.storageSize }
 // GetPrivate returns either the repository is private or not.
 func (r *Repository) GetPrivate() bool { return r.private }
 
+// GetVisibility returns the visibility of the repository.
+func (r *Repository) GetVisibility() string {
+	switch {
+	case r.visibility != "":
+		return r.visibility
+	case r.private:
+		return VisibilityPrivate
+	default:
+		return VisibilityPublic
+	}
+}
+
 type GenericProvider struct {
 	options Options
 }
@@ -94,5 +118,5 @@ func (p *GenericProvider) CloneRepository(
 	ctx context.Context,
 	cloner cloner.Cloner,
 	repository GitRepository) (string, error) {
-	return cloner.CloneRepository(ctx, repository.GetHTTPUrl())
+	return cloner.CloneRepository(ctx, repository.GetHTTPUrl(), nil)
 }
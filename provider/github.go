@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"srcfingerprint/cloner"
 	"strings"
@@ -21,11 +22,19 @@ const (
 
 // GitHubProvider is capable of gathering Github repositories from an org.
 type GitHubProvider struct {
-	client     *github.Client
-	options    Options
-	token      string
+	client      *github.Client
+	options     Options
+	tokenSource oauth2.TokenSource
+	// isInstallation is true when authenticated as a GitHub App installation
+	isInstallation bool
+}
+
+// githubGathering is the state of the gathering of the repositories of an org or a user.
+type githubGathering struct {
+	user       string
 	totalPages int
-	isOrg      bool
+	// isOrg is false once user is known not to be an org
+	isOrg bool
 }
 
 func createFromGithubRepo(r *github.Repository) *Repository {
@@ -36,15 +45,12 @@ func createFromGithubRepo(r *github.Repository) *Repository {
 		createdAt:   r.GetCreatedAt().Time,
 		storageSize: int64(r.GetSize()),
 		private:     r.GetPrivate(),
+		visibility:  r.GetVisibility(),
 	}
 }
 
-// NewGitHubProvider creates a new Github Provider.
-func NewGitHubProvider(token string, options Options) Provider {
-	client := github.NewClient(oauth2.NewClient(
-		context.Background(),
-		oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
-	))
+func newGitHubClient(httpClient *http.Client, options Options) *github.Client {
+	client := github.NewClient(httpClient)
 
 	if options.BaseURL != "" {
 		baseParsedURL, err := url.Parse(options.BaseURL)
@@ -55,19 +61,44 @@ func NewGitHubProvider(token string, options Options) Provider {
 		client.BaseURL = baseParsedURL
 	}
 
+	return client
+}
+
+func newGitHubProvider(tokenSource oauth2.TokenSource, options Options) *GitHubProvider {
+	// The oauth2 client sends its requests with the HTTP client of the context
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: options.httpTransport()})
+
 	return &GitHubProvider{
-		client:     client,
-		options:    options,
-		token:      token,
-		totalPages: unknownTotal,
-		isOrg:      true,
+		client:      newGitHubClient(oauth2.NewClient(ctx, tokenSource), options),
+		options:     options,
+		tokenSource: tokenSource,
+	}
+}
+
+// NewGitHubProvider creates a new Github Provider.
+func NewGitHubProvider(token string, options Options) Provider {
+	return newGitHubProvider(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), options)
+}
+
+// NewGitHubAppProvider creates a new Github Provider authenticated as a GitHub App installation.
+// Installation tokens are refreshed before they expire, for both API calls and clones.
+func NewGitHubAppProvider(credentials GitHubAppCredentials, options Options) (Provider, error) {
+	tokenSource, err := newGitHubAppTokenSource(credentials, options)
+	if err != nil {
+		return nil, err
 	}
+
+	provider := newGitHubProvider(tokenSource, options)
+	provider.isInstallation = true
+
+	return provider, nil
 }
 
 // Gather Page for GitHub provider.
 // If is first page update the total page count and try as user as well.
-func (p *GitHubProvider) gatherPage(user string, page int) ([]GitRepository, error) {
-	total := fmt.Sprint(p.totalPages)
+func (p *GitHubProvider) gatherPage(gathering *githubGathering, page int) ([]GitRepository, error) {
+	user := gathering.user
+	total := fmt.Sprint(gathering.totalPages)
 	if total == fmt.Sprint(unknownTotal) {
 		total = "?"
 	}
@@ -87,7 +118,10 @@ func (p *GitHubProvider) gatherPage(user string, page int) ([]GitRepository, err
 		visibility = "private"
 	}
 
-	if p.isOrg {
+	if p.isInstallation && user == "" {
+		// Installations can not list user repositories, but only the repositories they were granted
+		repos, resp, collectErr = p.listInstallationRepositories(page, visibility)
+	} else if gathering.isOrg {
 		opt := &github.RepositoryListByOrgOptions{
 			ListOptions: github.ListOptions{
 				PerPage: reposPerPage, Page: page,
@@ -97,11 +131,11 @@ func (p *GitHubProvider) gatherPage(user string, page int) ([]GitRepository, err
 		repos, resp, collectErr = p.client.Repositories.ListByOrg(context.Background(), user, opt)
 
 		if resp.StatusCode == 404 && page == 1 {
-			p.isOrg = false
+			gathering.isOrg = false
 		}
 	}
 
-	if !p.isOrg {
+	if !gathering.isOrg && !p.isInstallation {
 		opt := &github.RepositoryListOptions{
 			ListOptions: github.ListOptions{
 				PerPage: reposPerPage, Page: page,
@@ -116,8 +150,8 @@ func (p *GitHubProvider) gatherPage(user string, page int) ([]GitRepository, err
 		return nil, collectErr
 	}
 
-	if p.totalPages == unknownTotal {
-		p.totalPages = resp.LastPage
+	if gathering.totalPages == unknownTotal {
+		gathering.totalPages = resp.LastPage
 	}
 
 	repositories := make([]GitRepository, 0, len(repos))
@@ -131,16 +165,123 @@ func (p *GitHubProvider) gatherPage(user string, page int) ([]GitRepository, err
 			continue
 		}
 
+		if !p.matchesFilters(repo) {
+			continue
+		}
+
 		repositories = append(repositories, createFromGithubRepo(repo))
 	}
 
 	return repositories, nil
 }
 
-// Gather gather user's git repositories and send them to outputChannel.
-func (p *GitHubProvider) Gather(user string) ([]GitRepository, error) {
+// matchesFilters returns true if the topics and the primary language of a repository match the options.
+func (p *GitHubProvider) matchesFilters(repo *github.Repository) bool {
+	if len(p.options.IncludeTopics) > 0 && !containsAnyFold(p.options.IncludeTopics, repo.Topics) {
+		return false
+	}
+
+	if containsAnyFold(p.options.ExcludeTopics, repo.Topics) {
+		return false
+	}
+
+	language := []string{repo.GetLanguage()}
+
+	if len(p.options.IncludeLanguages) > 0 && !containsAnyFold(p.options.IncludeLanguages, language) {
+		return false
+	}
+
+	return !containsAnyFold(p.options.ExcludeLanguages, language)
+}
+
+// containsAnyFold returns true if any of values is in list, case insensitively.
+func containsAnyFold(list []string, values []string) bool {
+	for _, value := range values {
+		for _, item := range list {
+			if strings.EqualFold(item, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// listInstallationRepositories lists the repositories accessible to a GitHub App installation.
+func (p *GitHubProvider) listInstallationRepositories(
+	page int,
+	visibility string) ([]*github.Repository, *github.Response, error) {
+	list, resp, err := p.client.Apps.ListRepos(context.Background(), &github.ListOptions{
+		PerPage: reposPerPage, Page: page,
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	repos := make([]*github.Repository, 0, len(list.Repositories))
+
+	for _, repo := range list.Repositories {
+		if visibility == "private" && !repo.GetPrivate() {
+			continue
+		}
+
+		repos = append(repos, repo)
+	}
+
+	return repos, resp, nil
+}
+
+// listOrgs lists the organizations visible to the token: every organization of the instance for
+// GitHub Enterprise, where /organizations is restricted to the instance, else the organizations of the user.
+func (p *GitHubProvider) listOrgs() ([]string, error) {
+	orgs := make([]string, 0)
+
+	if p.options.BaseURL == "" {
+		for page := 1; page != 0; {
+			list, resp, err := p.client.Organizations.List(context.Background(), "", &github.ListOptions{
+				PerPage: reposPerPage, Page: page,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, org := range list {
+				orgs = append(orgs, org.GetLogin())
+			}
+
+			page = resp.NextPage
+		}
+
+		return orgs, nil
+	}
+
+	opt := &github.OrganizationsListOptions{ListOptions: github.ListOptions{PerPage: reposPerPage}}
+
+	for {
+		list, _, err := p.client.Organizations.ListAll(context.Background(), opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(list) == 0 {
+			return orgs, nil
+		}
+
+		for _, org := range list {
+			orgs = append(orgs, org.GetLogin())
+		}
+
+		// /organizations is paginated by organization ID
+		opt.Since = list[len(list)-1].GetID()
+	}
+}
+
+// gatherUser gathers the repositories of an org or a user.
+func (p *GitHubProvider) gatherUser(user string) ([]GitRepository, error) {
 	log.Debugf("Gathering repositories for Github org %s\n", user)
 
+	gathering := &githubGathering{user: user, totalPages: unknownTotal, isOrg: true}
+
 	wg := sync.WaitGroup{}
 
 	var mu sync.Mutex
@@ -148,13 +289,13 @@ func (p *GitHubProvider) Gather(user string) ([]GitRepository, error) {
 	// repositories protected by mu, since multiple goroutines will access it
 	repositories := make([]GitRepository, 0)
 
-	for pageCount := 1; pageCount <= p.totalPages; pageCount++ {
+	for pageCount := 1; pageCount <= gathering.totalPages; pageCount++ {
 		wg.Add(1)
 
 		go func(page int) {
 			defer wg.Done()
 
-			pageRepositories, err := p.gatherPage(user, page)
+			pageRepositories, err := p.gatherPage(gathering, page)
 			if err != nil {
 				log.Errorf("Error gathering page %v:%v\n", page, err)
 
@@ -169,7 +310,7 @@ func (p *GitHubProvider) Gather(user string) ([]GitRepository, error) {
 		if pageCount == 1 {
 			wg.Wait()
 
-			if p.totalPages == unknownTotal {
+			if gathering.totalPages == unknownTotal {
 				return nil, fmt.Errorf("unable to gather total pages")
 			}
 		}
@@ -180,15 +321,83 @@ func (p *GitHubProvider) Gather(user string) ([]GitRepository, error) {
 	return repositories, nil
 }
 
+// Gather gathers the repositories of an org or a user, or of every org visible to the token
+// if the AllOrgs option is set.
+// A repository appearing through several paths, such as pages shifted by a new repository, is gathered once.
+func (p *GitHubProvider) Gather(user string) ([]GitRepository, error) {
+	users := []string{user}
+
+	// Installations list every repository they were granted, whatever the org
+	if p.options.AllOrgs && !p.isInstallation {
+		orgs, err := p.listOrgs()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list organizations: %w", err)
+		}
+
+		log.Infof("Gathering repositories of %v organizations\n", len(orgs))
+
+		users = orgs
+	}
+
+	seen := make(map[string]bool)
+	repositories := make([]GitRepository, 0)
+
+	for _, user := range users {
+		userRepositories, err := p.gatherUser(user)
+		if err != nil {
+			if len(users) == 1 {
+				return nil, err
+			}
+
+			log.Errorf("Error gathering org %v:%v\n", user, err)
+
+			continue
+		}
+
+		collected := 0
+		ignored := 0
+
+		for _, repository := range userRepositories {
+			if seen[repository.GetHTTPUrl()] {
+				continue
+			}
+
+			if p.options.OrgLimit > 0 && collected >= p.options.OrgLimit {
+				ignored++
+
+				continue
+			}
+
+			seen[repository.GetHTTPUrl()] = true
+			repositories = append(repositories, repository)
+			collected++
+		}
+
+		if ignored > 0 {
+			log.Warnf("Limit reached for org %v: collected %d repos, ignored %d repos.\n", user, collected, ignored)
+		}
+	}
+
+	return repositories, nil
+}
+
 // CloneRepository clones a Github repository given the token. The token must have the `read_repository` rights.
-func (p *GitHubProvider) CloneRepository(ctx context.Context, cloner cloner.Cloner,
+func (p *GitHubProvider) CloneRepository(ctx context.Context, srcCloner cloner.Cloner,
 	repository GitRepository) (string, error) {
-	url := repository.GetSSHUrl()
-	// If token doesn't exist or if SSH cloning was specified, don't try to basic auth
-	if p.token != "" && !p.options.SSHCloning {
-		url = repository.GetHTTPUrl()
-		url = strings.Replace(url, "https://", fmt.Sprintf("https://x-access-token:%s@", p.token), 1)
+	if p.options.SSHCloning {
+		return srcCloner.CloneRepository(ctx, repository.GetSSHUrl(), nil)
+	}
+
+	// The token is retrieved for each clone, as GitHub App installation tokens expire
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return "", err
+	}
+
+	// If token doesn't exist, don't try to basic auth
+	if token.AccessToken == "" {
+		return srcCloner.CloneRepository(ctx, repository.GetSSHUrl(), nil)
 	}
 
-	return cloner.CloneRepository(ctx, url)
+	return srcCloner.CloneRepository(ctx, repository.GetHTTPUrl(), cloner.BasicAuth("x-access-token", token.AccessToken))
 }
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"srcfingerprint/redact"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// githubAppJWTDuration is the validity of the JWTs authenticating as a GitHub App, 10 minutes at most.
+	githubAppJWTDuration = 9 * time.Minute
+	// githubAppClockDrift is subtracted from the JWTs issue date to allow for clock drift.
+	githubAppClockDrift = time.Minute
+	// githubAppTokenRefreshMargin is the time before expiry at which an installation token is refreshed,
+	// so a token does not expire during a clone.
+	githubAppTokenRefreshMargin = 5 * time.Minute
+)
+
+// ErrInvalidPrivateKey is the error returned when a GitHub App private key can not be parsed.
+var ErrInvalidPrivateKey = errors.New("invalid GitHub App private key, expected a PEM encoded RSA key")
+
+// GitHubAppCredentials are the credentials of a GitHub App installation.
+type GitHubAppCredentials struct {
+	AppID          int64
+	InstallationID int64
+	// PrivateKey is the PEM encoded private key of the App.
+	PrivateKey []byte
+}
+
+// githubAppTransport authenticates the requests as a GitHub App with a JWT.
+type githubAppTransport struct {
+	T          http.RoundTripper
+	appID      int64
+	privateKey *rsa.PrivateKey
+}
+
+func (t *githubAppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrip must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	return t.T.RoundTrip(req)
+}
+
+// jwt creates a JWT signed with RS256, as required by GitHub.
+func (t *githubAppTransport) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-githubAppClockDrift).Unix(),
+		"exp": now.Add(githubAppJWTDuration).Unix(),
+		"iss": t.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// githubAppTokenSource creates installation tokens of a GitHub App.
+type githubAppTokenSource struct {
+	client         *github.Client
+	installationID int64
+}
+
+func (s *githubAppTokenSource) Token() (*oauth2.Token, error) {
+	log.Debugf("Creating a token for the GitHub App installation %v\n", s.installationID)
+
+	token, _, err := s.client.Apps.CreateInstallationToken(context.Background(), s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a GitHub App installation token: %w", err)
+	}
+
+	redact.AddSecret(token.GetToken())
+
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		Expiry:      token.GetExpiresAt().Add(-githubAppTokenRefreshMargin),
+	}, nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	return rsaKey, nil
+}
+
+// newGitHubAppTokenSource creates a token source refreshing the installation tokens before they expire.
+func newGitHubAppTokenSource(credentials GitHubAppCredentials, options Options) (oauth2.TokenSource, error) {
+	privateKey, err := parseRSAPrivateKey(credentials.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := newGitHubClient(&http.Client{
+		Transport: &githubAppTransport{
+			T:          options.httpTransport(),
+			appID:      credentials.AppID,
+			privateKey: privateKey,
+		},
+	}, options)
+
+	return oauth2.ReuseTokenSource(nil, &githubAppTokenSource{
+		client:         client,
+		installationID: credentials.InstallationID,
+	}), nil
+}
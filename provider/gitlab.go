@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"srcfingerprint/cloner"
 	"strings"
 	"sync"
@@ -35,7 +36,12 @@ func NewGitLabProvider(token string, options Options) Provider {
 		GitLabBaseURL = options.BaseURL
 	}
 
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(GitLabBaseURL))
+	clientOptions := []gitlab.ClientOptionFunc{gitlab.WithBaseURL(GitLabBaseURL)}
+	if options.Transport != nil {
+		clientOptions = append(clientOptions, gitlab.WithHTTPClient(&http.Client{Transport: options.Transport}))
+	}
+
+	client, err := gitlab.NewClient(token, clientOptions...)
 	if err != nil {
 		panic(fmt.Sprintf("could not set base URL for gitlab client: %v", err))
 	}
@@ -50,7 +56,8 @@ func NewGitLabProvider(token string, options Options) Provider {
 func createFromGitlabRepo(r *gitlab.Project) *Repository {
 	storageSize := int64(0)
 	if r.Statistics != nil {
-		storageSize = r.Statistics.RepositorySize
+		// GitLab reports the size in bytes, other providers in KB
+		storageSize = r.Statistics.RepositorySize / 1024
 	}
 
 	return &Repository{
@@ -60,6 +67,7 @@ func createFromGitlabRepo(r *gitlab.Project) *Repository {
 		createdAt:   *r.CreatedAt,
 		storageSize: storageSize,
 		private:     !r.Public,
+		visibility:  string(r.Visibility),
 	}
 }
 
@@ -244,14 +252,12 @@ func (p *GitLabProvider) collectFromGroup(repositories []GitRepository,
 // CloneRepository clones a Gitlab repository given the token. The token must have the `read_repository` rights.
 func (p *GitLabProvider) CloneRepository(
 	ctx context.Context,
-	cloner cloner.Cloner,
+	srcCloner cloner.Cloner,
 	repository GitRepository) (string, error) {
-	url := repository.GetSSHUrl()
 	// If token doesn't exist or if SSH cloning was specified, don't try to basic auth
-	if p.token != "" && !p.options.SSHCloning {
-		url = repository.GetHTTPUrl()
-		url = strings.Replace(url, "https://", fmt.Sprintf("https://%s:%s@", p.token, p.token), 1)
+	if p.token == "" || p.options.SSHCloning {
+		return srcCloner.CloneRepository(ctx, repository.GetSSHUrl(), nil)
 	}
 
-	return cloner.CloneRepository(ctx, url)
+	return srcCloner.CloneRepository(ctx, repository.GetHTTPUrl(), cloner.BasicAuth("oauth2", p.token))
 }
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"srcfingerprint/cloner"
@@ -21,13 +22,24 @@ type GitRepository interface {
 	// GetCreatedAt is the time of creation of the repository
 	GetCreatedAt() time.Time
 
-	// GetStorageSiwe is the size of the repository
+	// GetStorageSize is the size of the repository in KB, 0 if unknown.
 	GetStorageSize() int64
 
 	// GetPrivate returns either the repository is private or not.
 	GetPrivate() bool
+
+	// GetVisibility returns the visibility of the repository: VisibilityPublic, VisibilityPrivate or VisibilityInternal.
+	GetVisibility() string
 }
 
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+	// VisibilityInternal is the visibility of GitHub Enterprise repositories visible to all the members of the enterprise.
+	// Internal repositories are private.
+	VisibilityInternal = "internal"
+)
+
 // Provider is the interface to implement for a Git provider.
 type Provider interface {
 	// Gather is the function gathering git repositories given an user
@@ -40,7 +52,7 @@ type Provider interface {
 // Options represents options for the Provider.
 type Options struct {
 	// IncludeForkedRepos will include fork repositories in fingerprints computation
-	// This is available for GitLab and GitHub providers only.
+	// This is not available for the generic repository provider.
 	IncludeForkedRepos bool
 	// IncludeArchivedRepos will include archived repositories in fingerprints computation
 	// This is only available for GitHub provider only.
@@ -50,10 +62,30 @@ type Options struct {
 	IncludePublicRepos bool
 	// Repository private status to display in the output if the provider is 'repository'
 	RespositoryIsPrivate bool
+	// IncludeTopics keeps only the repositories with at least one of these topics, if not empty.
+	// This is only available for GitHub provider only.
+	IncludeTopics []string
+	// ExcludeTopics excludes the repositories with any of these topics.
+	// This is only available for GitHub provider only.
+	ExcludeTopics []string
+	// IncludeLanguages keeps only the repositories with one of these primary languages, if not empty.
+	// This is only available for GitHub provider only.
+	IncludeLanguages []string
+	// ExcludeLanguages excludes the repositories with one of these primary languages.
+	// This is only available for GitHub provider only.
+	ExcludeLanguages []string
+	// AllOrgs gathers the repositories of every organization visible to the token, instead of an object.
+	// This is only available for GitHub provider only.
+	AllOrgs bool
+	// OrgLimit is the maximum number of repositories gathered for each organization with AllOrgs, 0 for unlimited.
+	OrgLimit int
 	// Use SSH to clone repositories.
 	SSHCloning bool
 	// BaseURL is the base URL of the API
 	BaseURL string
 	// Repository name to display in the output if the provider is 'repository'
 	RepositoryName string
+	// Transport is the HTTP transport of the API clients, http.DefaultTransport if nil.
+	// See NewHTTPTransport.
+	Transport http.RoundTripper
 }
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"srcfingerprint/cloner"
+)
+
+// NewHTTPTransport creates a transport for the API clients, applying the same HTTP options as the cloner.
+func NewHTTPTransport(options cloner.HTTPOptions) (*http.Transport, error) {
+	// The default transport uses the proxy environment variables
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.Proxy != "" {
+		proxyURL, err := url.Parse(options.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL, expected a URL such as http://proxy:3128: %s", options.Proxy)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if options.CABundle == "" && !options.InsecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: options.InsecureSkipVerify, // nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if options.CABundle != "" {
+		bundle, err := os.ReadFile(options.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no PEM certificate found in CA bundle: %s", options.CABundle)
+		}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// httpTransport returns the transport of the API clients.
+func (o Options) httpTransport() http.RoundTripper {
+	if o.Transport == nil {
+		return http.DefaultTransport
+	}
+
+	return o.Transport
+}
@@ -0,0 +1,89 @@
+package srcfingerprint
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// RefsAll selects all the refs of the repository.
+	RefsAll = "all"
+	// RefsDefault selects the default branch of the repository.
+	RefsDefault = "default"
+	// RefsBranches selects all the branches of the repository.
+	RefsBranches = "branches"
+	// RefsTags selects all the tags of the repository.
+	RefsTags = "tags"
+
+	refsPrefix   = "refs/"
+	branchPrefix = "refs/heads/"
+	// remoteBranchPrefix is the prefix of the branches of a clone, except the default branch
+	remoteBranchPrefix = "refs/remotes/origin/"
+)
+
+// ValidateRefs returns an error if one of refs is neither a keyword nor a ref pattern.
+func ValidateRefs(refs []string) error {
+	for _, ref := range refs {
+		switch ref {
+		case RefsAll, RefsDefault, RefsBranches, RefsTags:
+		default:
+			if !strings.HasPrefix(ref, refsPrefix) {
+				return fmt.Errorf("invalid ref '%s', expected %s, %s, %s, %s or a pattern such as refs/heads/release/*",
+					ref, RefsAll, RefsDefault, RefsBranches, RefsTags)
+			}
+		}
+	}
+
+	return nil
+}
+
+// revisions returns the arguments of git rev-list selecting refs in the repository at path, --all if refs is empty.
+// Patterns of branches, such as refs/heads/release/*, also match the branches of the clone under refs/remotes/origin/.
+func revisions(path string, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return []string{"--all"}, nil
+	}
+
+	args := make([]string, 0, len(refs))
+	patterns := make([]string, 0)
+
+	for _, ref := range refs {
+		switch ref {
+		case RefsAll:
+			args = append(args, "--all")
+		case RefsDefault:
+			args = append(args, "HEAD")
+		case RefsBranches:
+			args = append(args, "--branches", "--remotes")
+		case RefsTags:
+			args = append(args, "--tags")
+		default:
+			patterns = append(patterns, ref)
+
+			if strings.HasPrefix(ref, branchPrefix) {
+				patterns = append(patterns, remoteBranchPrefix+strings.TrimPrefix(ref, branchPrefix))
+			}
+		}
+	}
+
+	if len(patterns) == 0 {
+		return args, nil
+	}
+
+	// for-each-ref matches both exact refs and glob patterns, and ignores the patterns matching no ref
+	cmd := exec.Command("git", append([]string{"for-each-ref", "--format=%(refname)", "--"}, patterns...)...)
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the refs: %w", err)
+	}
+
+	return append(args, strings.Fields(string(output))...), nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
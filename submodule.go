@@ -0,0 +1,276 @@
+package srcfingerprint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"path"
+	"srcfingerprint/provider"
+	"strings"
+	"sync"
+)
+
+const (
+	gitlinkMode       = "160000"
+	gitmodulesPath    = ".gitmodules"
+	submoduleCfgStart = "submodule."
+)
+
+// submoduleCollector collects the submodules referenced by the trees of a repository.
+type submoduleCollector struct {
+	// path is the path of the repository
+	path     string
+	gitlinks []*GitFile
+	seen     map[string]bool
+	// urls are the submodules URLs by submodule path
+	urls map[string]string
+}
+
+func newSubmoduleCollector(path string) *submoduleCollector {
+	return &submoduleCollector{
+		path:     path,
+		gitlinks: make([]*GitFile, 0),
+		seen:     make(map[string]bool),
+		urls:     make(map[string]string),
+	}
+}
+
+// AddTree collects the gitlinks of a tree.
+func (s *submoduleCollector) AddTree(objects *catFileBatch, tree *GitFile) error {
+	entries, err := readTree(objects, tree.Sha)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.mode != gitlinkMode {
+			continue
+		}
+
+		filepath := path.Join(tree.Filepath, entry.name)
+		if s.seen[filepath+entry.sha] {
+			continue
+		}
+
+		s.seen[filepath+entry.sha] = true
+		s.gitlinks = append(s.gitlinks, &GitFile{Sha: entry.sha, Type: GitFileTypeSubmodule, Filepath: filepath})
+	}
+
+	return nil
+}
+
+// AddGitmodules collects the submodules URLs from a .gitmodules blob.
+// URLs from the first blobs added take precedence.
+func (s *submoduleCollector) AddGitmodules(sha string) error {
+	cmd := exec.Command("git", "config", "--blob", sha, "--list")
+	cmd.Dir = s.path
+
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	paths := make(map[string]string)
+	urls := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := cutString(scanner.Text(), "=")
+		if !found || !strings.HasPrefix(key, submoduleCfgStart) {
+			continue
+		}
+
+		// Keys are submodule.<name>.path and submodule.<name>.url, the name can contain dots
+		key = strings.TrimPrefix(key, submoduleCfgStart)
+		separator := strings.LastIndex(key, ".")
+
+		if separator < 0 {
+			continue
+		}
+
+		switch name := key[:separator]; key[separator+1:] {
+		case "path":
+			paths[name] = value
+		case "url":
+			urls[name] = value
+		}
+	}
+
+	for name, submodulePath := range paths {
+		if _, exists := s.urls[submodulePath]; !exists && urls[name] != "" {
+			s.urls[submodulePath] = urls[name]
+		}
+	}
+
+	return nil
+}
+
+// Submodules returns the collected submodules, with their URLs when known.
+func (s *submoduleCollector) Submodules() []*GitFile {
+	for _, gitlink := range s.gitlinks {
+		gitlink.URL = s.urls[gitlink.Filepath]
+	}
+
+	return s.gitlinks
+}
+
+type treeEntry struct {
+	mode string
+	name string
+	sha  string
+}
+
+// readTree returns the entries of the tree sha.
+func readTree(objects *catFileBatch, sha string) ([]treeEntry, error) {
+	var entries []treeEntry
+
+	err := objects.Read(sha, func(content io.Reader, size int64) error {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+
+		// Object names are stored raw in trees
+		if entries, err = parseTree(data, len(sha)/2); err != nil {
+			return fmt.Errorf("unable to parse tree %s: %w", sha, err)
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// parseTree parses the content of a tree object, each entry being "<mode> <name>\0<raw object name>".
+func parseTree(data []byte, hashSize int) ([]treeEntry, error) {
+	entries := make([]treeEntry, 0)
+
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		if space < 0 {
+			return nil, errors.New("missing mode")
+		}
+
+		null := bytes.IndexByte(data, 0)
+		if null < space || len(data) < null+1+hashSize {
+			return nil, errors.New("truncated entry")
+		}
+
+		entries = append(entries, treeEntry{
+			mode: string(data[:space]),
+			name: string(data[space+1 : null]),
+			sha:  hex.EncodeToString(data[null+1 : null+1+hashSize]),
+		})
+		data = data[null+1+hashSize:]
+	}
+
+	return entries, nil
+}
+
+// parseRemoteURL returns the host and the path of a git remote, given as an URL or as a scp-like address.
+func parseRemoteURL(remote string) (host, repositoryPath string, ok bool) {
+	if strings.Contains(remote, "://") {
+		parsedURL, err := url.Parse(remote)
+		if err != nil || parsedURL.Host == "" {
+			return "", "", false
+		}
+
+		return parsedURL.Host, parsedURL.Path, true
+	}
+
+	// scp-like addresses are [user@]host:path
+	host, repositoryPath, found := cutString(remote, ":")
+	if !found || host == "" || strings.Contains(host, "/") {
+		return "", "", false
+	}
+
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+
+	return host, "/" + strings.TrimPrefix(repositoryPath, "/"), true
+}
+
+// hostname strips the port of a host.
+func hostname(host string) string {
+	return strings.ToLower((&url.URL{Host: host}).Hostname())
+}
+
+// repositoryKey identifies a repository regardless of the URL used to reach it.
+func repositoryKey(repository provider.GitRepository) string {
+	remote := repository.GetHTTPUrl()
+	if remote == "" {
+		remote = repository.GetSSHUrl()
+	}
+
+	host, repositoryPath, ok := parseRemoteURL(remote)
+	if !ok {
+		return remote
+	}
+
+	repositoryPath = strings.TrimSuffix(strings.TrimSuffix(repositoryPath, "/"), ".git")
+
+	return hostname(host) + strings.ToLower(repositoryPath)
+}
+
+// submoduleRepository returns the repository targeted by a submodule URL when it is hosted
+// on the same host as its parent repository.
+func submoduleRepository(parent provider.GitRepository, submoduleURL string) (provider.GitRepository, bool) {
+	host, parentPath, ok := parseRemoteURL(parent.GetHTTPUrl())
+	if !ok || submoduleURL == "" {
+		return nil, false
+	}
+
+	var repositoryPath string
+
+	if strings.HasPrefix(submoduleURL, "./") || strings.HasPrefix(submoduleURL, "../") {
+		// Relative URLs are resolved against the URL of the parent repository
+		repositoryPath = path.Join(parentPath, submoduleURL)
+	} else {
+		submoduleHost, submodulePath, ok := parseRemoteURL(submoduleURL)
+		if !ok || hostname(submoduleHost) != hostname(host) {
+			return nil, false
+		}
+
+		repositoryPath = submodulePath
+	}
+
+	repositoryPath = strings.TrimPrefix(path.Clean(repositoryPath), "/")
+
+	return provider.NewRepository(
+		strings.TrimSuffix(path.Base(repositoryPath), ".git"),
+		fmt.Sprintf("git@%s:%s", hostname(host), repositoryPath),
+		fmt.Sprintf("https://%s/%s", host, repositoryPath),
+		parent.GetPrivate(),
+	), true
+}
+
+// repositorySet is a set of repositories safe for concurrent use.
+type repositorySet struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func newRepositorySet() *repositorySet {
+	return &repositorySet{keys: make(map[string]bool)}
+}
+
+// Add adds a repository to the set, it returns false if the repository was already in the set.
+func (s *repositorySet) Add(repository provider.GitRepository) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := repositoryKey(repository)
+	if s.keys[key] {
+		return false
+	}
+
+	s.keys[key] = true
+
+	return true
+}
@@ -0,0 +1,53 @@
+package srcfingerprint
+
+import (
+	"srcfingerprint/provider"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SubmoduleTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SubmoduleTestSuite) TestSubmoduleRepository() {
+	parent := provider.NewRepository("app", "git@github.com:org/app.git", "https://github.com/org/app", true)
+
+	for _, submoduleURL := range []string{
+		"../lib.git",
+		"https://github.com/org/lib.git",
+		"git@github.com:org/lib.git",
+		"ssh://git@github.com/org/lib.git",
+	} {
+		repository, ok := submoduleRepository(parent, submoduleURL)
+
+		assert.True(suite.T(), ok, submoduleURL)
+		assert.Equal(suite.T(),
+			provider.NewRepository("lib", "git@github.com:org/lib.git", "https://github.com/org/lib.git", true),
+			repository, submoduleURL)
+	}
+}
+
+func (suite *SubmoduleTestSuite) TestSubmoduleRepositoryOtherHost() {
+	parent := provider.NewRepository("app", "git@github.com:org/app.git", "https://github.com/org/app", true)
+
+	_, ok := submoduleRepository(parent, "https://gitlab.com/org/lib.git")
+	assert.False(suite.T(), ok)
+
+	_, ok = submoduleRepository(parent, "")
+	assert.False(suite.T(), ok)
+}
+
+func (suite *SubmoduleTestSuite) TestRepositorySet() {
+	set := newRepositorySet()
+
+	assert.True(suite.T(), set.Add(provider.NewRepository("lib", "", "https://github.com/org/lib", false)))
+	assert.False(suite.T(), set.Add(provider.NewRepository("lib", "", "https://github.com/Org/lib.git", false)))
+	assert.True(suite.T(), set.Add(provider.NewRepository("lib", "", "https://github.com/other/lib", false)))
+}
+
+func TestSubmodule(t *testing.T) {
+	suite.Run(t, new(SubmoduleTestSuite))
+}
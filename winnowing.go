@@ -0,0 +1,239 @@
+package srcfingerprint
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// winnowingK is the length of the hashed k-grams of normalized content: shorter matches are ignored.
+	winnowingK = 25
+	// winnowingWindow is the number of consecutive k-gram hashes a fingerprint is selected from.
+	// Any match of at least winnowingK + winnowingWindow - 1 normalized characters shares a fingerprint.
+	winnowingWindow = 26
+	// winnowingMaxSize is the maximum size of the blobs fingerprinted, larger blobs are rarely hand written code.
+	winnowingMaxSize = 1024 * 1024
+
+	// winnowingHashBase is the base of the rolling hash of the k-grams.
+	winnowingHashBase = 1099511628211
+)
+
+// Fingerprint is a winnowing fingerprint: the hash of a k-gram of the normalized content of a file.
+type Fingerprint struct {
+	// Hash is the 64 bits hash of the k-gram, in hexadecimal
+	Hash string `json:"hash"`
+	// Line is the line of the file where the k-gram starts
+	Line int `json:"line"`
+}
+
+// commentStyle describes the comments and the string literals of a language.
+type commentStyle struct {
+	// line are the markers of comments ending at the end of the line
+	line []string
+	// block are the opening and closing markers of block comments
+	block [][2]string
+	// quotes are the delimiters of string literals, which may contain comment markers
+	quotes string
+}
+
+var (
+	cCommentStyle      = commentStyle{line: []string{"//"}, block: [][2]string{{"/*", "*/"}}, quotes: "\"'`"}
+	hashCommentStyle   = commentStyle{line: []string{"#"}, quotes: "\"'"}
+	pythonCommentStyle = commentStyle{
+		line:   []string{"#"},
+		block:  [][2]string{{`"""`, `"""`}, {"'''", "'''"}},
+		quotes: "\"'",
+	}
+	sqlCommentStyle  = commentStyle{line: []string{"--"}, block: [][2]string{{"/*", "*/"}}, quotes: "'\""}
+	luaCommentStyle  = commentStyle{line: []string{"--"}, block: [][2]string{{"--[[", "]]"}}, quotes: "\"'"}
+	htmlCommentStyle = commentStyle{block: [][2]string{{"<!--", "-->"}}}
+)
+
+// normalize removes the comments and the whitespace of data, and returns the line of each remaining byte.
+// String literals are kept, except their spaces and tabs, so that comment markers in strings are not removed.
+func normalize(data []byte, style commentStyle) ([]byte, []int) {
+	normalized := make([]byte, 0, len(data))
+	lines := make([]int, 0, len(data))
+	line := 1
+
+	// skip advances over data[i:end], counting the lines
+	skip := func(i, end int) int {
+		line += bytes.Count(data[i:end], []byte{'\n'})
+
+		return end
+	}
+
+	for i := 0; i < len(data); {
+		if marker, end, ok := style.blockComment(data[i:]); ok {
+			closing := bytes.Index(data[i+len(marker):], []byte(end))
+			if closing < 0 {
+				break
+			}
+
+			i = skip(i, i+len(marker)+closing+len(end))
+
+			continue
+		}
+
+		if style.lineComment(data[i:]) {
+			newline := bytes.IndexByte(data[i:], '\n')
+			if newline < 0 {
+				break
+			}
+
+			i += newline + 1
+			line++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data[i:])
+
+		switch {
+		case r == '\n':
+			line++
+		case unicode.IsSpace(r):
+			// Whitespace is removed
+		case strings.ContainsRune(style.quotes, r):
+			// Keep the literal up to its closing quote, on the same line
+			end := i + size
+			for end < len(data) && data[end] != byte(r) && data[end] != '\n' {
+				if data[end] == '\\' && end+1 < len(data) && data[end+1] != '\n' {
+					end++
+				}
+				end++
+			}
+
+			if end < len(data) && data[end] == byte(r) {
+				end++
+			}
+
+			for _, b := range data[i:end] {
+				if b != ' ' && b != '\t' {
+					normalized = append(normalized, b)
+					lines = append(lines, line)
+				}
+			}
+
+			i = end
+
+			continue
+		default:
+			normalized = append(normalized, data[i:i+size]...)
+			for j := 0; j < size; j++ {
+				lines = append(lines, line)
+			}
+		}
+
+		i += size
+	}
+
+	return normalized, lines
+}
+
+func (s commentStyle) blockComment(data []byte) (string, string, bool) {
+	for _, block := range s.block {
+		if bytes.HasPrefix(data, []byte(block[0])) {
+			return block[0], block[1], true
+		}
+	}
+
+	return "", "", false
+}
+
+func (s commentStyle) lineComment(data []byte) bool {
+	for _, marker := range s.line {
+		if bytes.HasPrefix(data, []byte(marker)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// winnow returns the winnowing fingerprints of data, see "Winnowing: Local Algorithms for Document Fingerprinting".
+// The content is normalized first, so that the fingerprints do not depend on the formatting and the comments.
+func winnow(data []byte, style commentStyle) []Fingerprint {
+	normalized, lines := normalize(data, style)
+	hashes := kgramHashes(normalized, winnowingK)
+
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	window := winnowingWindow
+	if len(hashes) < window {
+		window = len(hashes)
+	}
+
+	fingerprints := make([]Fingerprint, 0, 2*len(hashes)/(window+1)+1)
+	selected := -1
+
+	for end := window - 1; end < len(hashes); end++ {
+		start := end - window + 1
+
+		switch {
+		case selected < start:
+			// The selected hash left the window, select the rightmost minimal hash of the window
+			selected = start
+			for i := start + 1; i <= end; i++ {
+				if hashes[i] <= hashes[selected] {
+					selected = i
+				}
+			}
+		case hashes[end] <= hashes[selected]:
+			selected = end
+		default:
+			continue
+		}
+
+		fingerprints = append(fingerprints, Fingerprint{Hash: fmt.Sprintf("%016x", hashes[selected]), Line: lines[selected]})
+	}
+
+	return fingerprints
+}
+
+// kgramHashes returns the hashes of the k-grams of data, computed with a rolling hash.
+func kgramHashes(data []byte, k int) []uint64 {
+	if len(data) < k {
+		return nil
+	}
+
+	// power is winnowingHashBase^(k-1), the weight of the first byte of a k-gram
+	power := uint64(1)
+	for i := 1; i < k; i++ {
+		power *= winnowingHashBase
+	}
+
+	hashes := make([]uint64, 0, len(data)-k+1)
+
+	var hash uint64
+
+	for i, b := range data {
+		if i >= k {
+			hash -= uint64(data[i-k]) * power
+		}
+
+		hash = hash*winnowingHashBase + uint64(b)
+
+		if i >= k-1 {
+			hashes = append(hashes, mixHash(hash))
+		}
+	}
+
+	return hashes
+}
+
+// mixHash spreads the bits of a rolling hash, whose low bits only depend on the low bits of the bytes.
+func mixHash(hash uint64) uint64 {
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+
+	return hash
+}
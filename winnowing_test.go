@@ -0,0 +1,88 @@
+package srcfingerprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WinnowingTestSuite struct {
+	suite.Suite
+}
+
+const winnowingGoSource = `package main
+
+import "fmt"
+
+// greet prints a greeting for each name
+func greet(names []string) {
+	for index, name := range names {
+		fmt.Printf("hello %s, you are number %d // not a comment\n", name, index)
+	}
+}
+`
+
+// fingerprintHashes returns the set of the hashes of fingerprints.
+func fingerprintHashes(fingerprints []Fingerprint) map[string]bool {
+	hashes := make(map[string]bool)
+	for _, fingerprint := range fingerprints {
+		hashes[fingerprint.Hash] = true
+	}
+
+	return hashes
+}
+
+func (suite *WinnowingTestSuite) TestNormalize() {
+	normalized, lines := normalize([]byte(winnowingGoSource), commentStyleOf("Go"))
+
+	assert.Equal(suite.T(),
+		`packagemainimport"fmt"funcgreet(names[]string){forindex,name:=rangenames{`+
+			`fmt.Printf("hello%s,youarenumber%d//notacomment\n",name,index)}}`,
+		string(normalized))
+	assert.Len(suite.T(), lines, len(normalized))
+	assert.Equal(suite.T(), 1, lines[0])
+	assert.Equal(suite.T(), 10, lines[len(lines)-1])
+
+	normalized, _ = normalize([]byte("def f():\n    \"\"\"Docstring\"\"\"\n    return '#' # comment\n"), commentStyleOf("Python"))
+	assert.Equal(suite.T(), "deff():return'#'", string(normalized))
+}
+
+func (suite *WinnowingTestSuite) TestWinnowReformatted() {
+	reformatted := strings.NewReplacer("\t", "  ", "// greet prints a greeting for each name\n", "/* greet */").
+		Replace(winnowingGoSource)
+
+	fingerprints := winnow([]byte(winnowingGoSource), commentStyleOf("Go"))
+
+	assert.NotEmpty(suite.T(), fingerprints)
+	assert.Equal(suite.T(),
+		fingerprintHashes(fingerprints),
+		fingerprintHashes(winnow([]byte(reformatted), commentStyleOf("Go"))))
+}
+
+func (suite *WinnowingTestSuite) TestWinnowPartialCopy() {
+	original := fingerprintHashes(winnow([]byte(winnowingGoSource), commentStyleOf("Go")))
+	copied := fingerprintHashes(winnow(
+		[]byte("package other\n\nfunc other() int {\n\treturn 42\n}\n"+winnowingGoSource[strings.Index(winnowingGoSource, "func"):]),
+		commentStyleOf("Go")))
+
+	shared := 0
+
+	for hash := range copied {
+		if original[hash] {
+			shared++
+		}
+	}
+
+	assert.Greater(suite.T(), shared, 0)
+	assert.Less(suite.T(), shared, len(copied))
+}
+
+func (suite *WinnowingTestSuite) TestWinnowShortContent() {
+	assert.Empty(suite.T(), winnow([]byte("package main\n"), commentStyleOf("Go")))
+}
+
+func TestWinnowing(t *testing.T) {
+	suite.Run(t, new(WinnowingTestSuite))
+}